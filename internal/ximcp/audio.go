@@ -1,8 +1,10 @@
 package ximcp
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -11,9 +13,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/gopxl/beep/v2"
-	"github.com/gopxl/beep/v2/mp3"
-	"github.com/gopxl/beep/v2/speaker"
 	"github.com/taigrr/elevenlabs/client/types"
 )
 
@@ -25,48 +24,263 @@ func generateRandomHex(length int) (string, error) {
 	return fmt.Sprintf("%x", bytes)[:length], nil
 }
 
-func (s *Server) GenerateAudio(text string) (string, error) {
+// GeneratedAudio describes a clip produced by GenerateAudio.
+type GeneratedAudio struct {
+	FilePath     string
+	VoiceID      string
+	VoiceName    string
+	ModelID      string
+	OutputFormat string
+	Settings     types.SynthesisOptions
+	Chunks       int
+	// SourceFile is the recording a voice conversion was made from.
+	SourceFile string
+	// Duration is zero when the saved clip could not be measured.
+	Duration time.Duration
+	// QueueID identifies the playback queue entry for streamed clips.
+	QueueID uint64
+}
+
+// speechTarget is the voice, format, and settings a request renders with.
+type speechTarget struct {
+	voice   types.VoiceResponseModel
+	format  AudioFormat
+	options types.SynthesisOptions
+}
+
+// speechJob is a validated text-to-speech request ready for synthesis.
+type speechJob struct {
+	speechTarget
+	text    string
+	chunks  []string
+	modelID string
+}
+
+// prepareSpeech resolves the voice, model, format, and settings for text.
+func (s *Server) prepareSpeech(ctx context.Context, text string, speechOptions SpeechOptions) (*speechJob, error) {
 	if strings.TrimSpace(text) == "" {
-		return "", fmt.Errorf("text is required")
+		return nil, fmt.Errorf("text is required")
+	}
+
+	modelID, err := s.resolveModelID(speechOptions.ModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolveSpeechTarget(ctx, speechOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &speechJob{
+		speechTarget: target,
+		text:         text,
+		chunks:       splitText(text, s.maxChunkCharacters()),
+		modelID:      modelID,
+	}, nil
+}
+
+// resolveSpeechTarget resolves the voice, format, and settings of a request.
+// Settings start from the voice's saved settings, then the server defaults,
+// then the per-call overrides.
+func (s *Server) resolveSpeechTarget(ctx context.Context, speechOptions SpeechOptions) (speechTarget, error) {
+	format, err := s.resolveOutputFormat(speechOptions.OutputFormat)
+	if err != nil {
+		return speechTarget{}, err
+	}
+
+	voice, err := s.speechVoice(speechOptions.Voice)
+	if err != nil {
+		return speechTarget{}, err
+	}
+
+	base, err := s.applyDefaultSettings(s.savedVoiceSettings(ctx, voice.VoiceID))
+	if err != nil {
+		return speechTarget{}, err
+	}
+
+	options, err := speechOptions.VoiceSettings.apply(base)
+	if err != nil {
+		return speechTarget{}, err
+	}
+
+	return speechTarget{voice: voice, format: format, options: options}, nil
+}
+
+// resolveOutputFormat returns the format named by override, or the server's
+// default format.
+func (s *Server) resolveOutputFormat(override string) (AudioFormat, error) {
+	if override = strings.TrimSpace(override); override != "" {
+		return parseOutputFormat(override)
+	}
+	return parseOutputFormat(s.outputFormat)
+}
+
+// speechVoice returns the voice named by override, or the current voice.
+func (s *Server) speechVoice(override string) (types.VoiceResponseModel, error) {
+	if err := s.ensureVoices(); err != nil {
+		return types.VoiceResponseModel{}, err
+	}
+
+	s.voicesMutex.RLock()
+	defer s.voicesMutex.RUnlock()
+
+	if strings.TrimSpace(override) != "" {
+		voice, err := s.findVoice(override)
+		if err != nil {
+			return types.VoiceResponseModel{}, err
+		}
+		return *voice, nil
 	}
 
 	if s.currentVoice == nil {
-		return "", fmt.Errorf("no voice selected")
+		return types.VoiceResponseModel{}, fmt.Errorf("no voice selected")
 	}
+	return *s.currentVoice, nil
+}
 
-	audioData, err := s.generateTTSAudio(text)
+func (job *speechJob) metadata() AudioMetadata {
+	return AudioMetadata{
+		VoiceID:      job.voice.VoiceID,
+		VoiceName:    job.voice.Name,
+		ModelID:      job.modelID,
+		OutputFormat: job.format.Name,
+		Settings:     &job.options,
+	}
+}
+
+func (job *speechJob) result(filePath string, duration time.Duration) *GeneratedAudio {
+	return &GeneratedAudio{
+		FilePath:     filePath,
+		VoiceID:      job.voice.VoiceID,
+		VoiceName:    job.voice.Name,
+		ModelID:      job.modelID,
+		OutputFormat: job.format.Name,
+		Settings:     job.options,
+		Chunks:       len(job.chunks),
+		Duration:     duration,
+	}
+}
+
+func (s *Server) GenerateAudio(ctx context.Context, text string, speechOptions SpeechOptions, progress ProgressFunc) (*GeneratedAudio, error) {
+	job, err := s.prepareSpeech(ctx, text, speechOptions)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	filePath, err := s.saveAudioFiles(text, audioData)
+	var audioData bytes.Buffer
+	if err := s.synthesizeChunks(ctx, job.chunks, job.voice.VoiceID, job.modelID, job.format.Name, job.options, progress, newSampleConverter(&audioData, job.format)); err != nil {
+		return nil, err
+	}
+
+	filePath, err := s.saveAudioFiles(ctx, job.text, audioData.Bytes(), job.metadata())
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return filePath, nil
+	return job.result(filePath, s.clipDuration(filePath)), nil
 }
 
-func (s *Server) generateTTSAudio(text string) ([]byte, error) {
-	audioStream, err := s.client.TTS(context.Background(), text, s.currentVoice.VoiceID, "", types.SynthesisOptions{
-		Stability:       DefaultStability,
-		SimilarityBoost: DefaultSimilarityBoost,
+// StreamAudio generates audio like GenerateAudio, but queues it for playback
+// so it starts as soon as the first frames arrive while the clip is still
+// being written to disk. Formats that cannot be played are only saved, and
+// the result has no QueueID.
+func (s *Server) StreamAudio(ctx context.Context, text string, speechOptions SpeechOptions, priority QueuePriority, progress ProgressFunc) (*GeneratedAudio, error) {
+	job, err := s.prepareSpeech(ctx, text, speechOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := streamDecoder(job.format); err != nil {
+		filePath, err := s.streamSpeech(ctx, job, progress, io.Discard, nil)
+		if err != nil {
+			return nil, err
+		}
+		return job.result(filePath, s.clipDuration(filePath)), nil
+	}
+
+	var entry *QueueEntry
+	playback := newAudioBuffer()
+	filePath, err := s.streamSpeech(ctx, job, progress, playback, func(filePath string) error {
+		var err error
+		entry, err = s.EnqueueStream(filePath, playback.NewReader(), job.format, priority)
+		return err
 	})
+	playback.CloseWithError(err)
 	if err != nil {
 		return nil, err
 	}
-	defer audioStream.Close()
 
-	audioData, err := io.ReadAll(audioStream)
+	audio := job.result(filePath, s.clipDuration(filePath))
+	audio.QueueID = entry.ID
+	return audio, nil
+}
+
+// streamSpeech synthesizes job directly into a new audio file, copying the
+// audio to tee as it arrives, without the WAV header of PCM clips.
+// started is called once the file has been created; if it fails, synthesis
+// is abandoned.
+func (s *Server) streamSpeech(ctx context.Context, job *speechJob, progress ProgressFunc, tee io.Writer, started func(filePath string) error) (string, error) {
+	filePath, err := s.generateFilePath(s.audioDirectory(ctx), job.format.Extension())
 	if err != nil {
-		return nil, fmt.Errorf("failed to read audio stream: %w", err)
+		return "", err
+	}
+
+	if err := s.ensureDirectoryExists(filePath); err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+
+	clip, err := newClipWriter(file, job.format)
+	if err != nil {
+		file.Close()
+		os.Remove(filePath)
+		return "", err
+	}
+
+	if started != nil {
+		if err := started(filePath); err != nil {
+			file.Close()
+			os.Remove(filePath)
+			return "", err
+		}
+	}
+
+	err = s.synthesizeChunks(ctx, job.chunks, job.voice.VoiceID, job.modelID, job.format.Name, job.options, progress, newSampleConverter(io.MultiWriter(clip, tee), job.format))
+	if closeErr := clip.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write audio file: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(filePath)
+		return "", err
+	}
+
+	if err := s.writeTextFile(filePath, job.text); err != nil {
+		return "", err
+	}
+
+	if err := s.writeMetadataFile(filePath, job.metadata()); err != nil {
+		return "", err
 	}
 
-	return audioData, nil
+	s.clipSaved(ctx)
+	return filePath, nil
 }
 
-func (s *Server) saveAudioFiles(text string, audioData []byte) (string, error) {
-	filePath, err := s.generateFilePath()
+// saveAudioFiles saves audioData in the format recorded in metadata, adding a
+// WAV header to PCM samples, along with its sidecar files.
+func (s *Server) saveAudioFiles(ctx context.Context, text string, audioData []byte, metadata AudioMetadata) (string, error) {
+	format, err := parseOutputFormat(metadata.OutputFormat)
+	if err != nil {
+		return "", err
+	}
+	audioData = encodeClip(format, audioData)
+
+	filePath, err := s.generateFilePath(s.audioDirectory(ctx), format.Extension())
 	if err != nil {
 		return "", err
 	}
@@ -83,17 +297,31 @@ func (s *Server) saveAudioFiles(text string, audioData []byte) (string, error) {
 		return "", err
 	}
 
+	if err := s.writeMetadataFile(filePath, metadata); err != nil {
+		return "", err
+	}
+
+	s.clipSaved(ctx)
 	return filePath, nil
 }
 
-func (s *Server) generateFilePath() (string, error) {
+// clipSaved applies the retention policy and tells clients to list the
+// resources again.
+func (s *Server) clipSaved(ctx context.Context) {
+	if _, err := s.pruneAudioFiles(s.audioDirectory(ctx)); err != nil {
+		log.Printf("Error applying retention policy: %v", err)
+	}
+	s.notifyResourcesChanged()
+}
+
+func (s *Server) generateFilePath(directory, extension string) (string, error) {
 	timestamp := time.Now().UnixMilli()
 	randomHex, err := generateRandomHex(RandomHexLength)
 	if err != nil {
 		return "", err
 	}
-	filename := fmt.Sprintf("%d-%s.mp3", timestamp, randomHex)
-	return filepath.Join(AudioDirectory, filename), nil
+	filename := fmt.Sprintf("%d-%s%s", timestamp, randomHex, extension)
+	return filepath.Join(directory, filename), nil
 }
 
 func (s *Server) ensureDirectoryExists(filePath string) error {
@@ -111,82 +339,70 @@ func (s *Server) writeAudioFile(filePath string, audioData []byte) error {
 }
 
 func (s *Server) writeTextFile(filePath, text string) error {
-	textFilePath := strings.TrimSuffix(filePath, ".mp3") + ".txt"
+	textFilePath := trimAudioExtension(filePath) + ".txt"
 	if err := os.WriteFile(textFilePath, []byte(text), 0644); err != nil {
 		return fmt.Errorf("failed to write text file: %w", err)
 	}
 	return nil
 }
 
-func (s *Server) PlayAudio(filepath string) error {
-	if err := validateAudioFilePath(filepath); err != nil {
-		return err
+// writeMetadataFile records metadata for the clip at filePath, measuring its
+// playing time once so history doesn't have to decode it again.
+func (s *Server) writeMetadataFile(filePath string, metadata AudioMetadata) error {
+	if metadata.CreatedAt.IsZero() {
+		metadata.CreatedAt = time.Now().UTC()
 	}
-
-	s.playMutex.Lock()
-	defer s.playMutex.Unlock()
-
-	file, err := os.Open(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to open audio file: %w", err)
+	if metadata.DurationSeconds == 0 {
+		if duration, err := audioDuration(filePath); err == nil {
+			metadata.DurationSeconds = duration.Seconds()
+		}
 	}
-	defer file.Close()
 
-	streamer, format, err := mp3.Decode(file)
+	data, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to decode mp3: %w", err)
+		return fmt.Errorf("failed to encode metadata: %w", err)
 	}
-	defer streamer.Close()
-
-	return s.playStreamer(streamer, format)
-}
-
-func (s *Server) playStreamer(streamer beep.StreamSeekCloser, format beep.Format) error {
-	resampled := beep.Resample(4, format.SampleRate, AudioSampleRate, streamer)
-
-	done := make(chan bool)
-	speaker.Play(beep.Seq(resampled, beep.Callback(func() {
-		done <- true
-	})))
 
-	<-done
+	metadataFilePath := trimAudioExtension(filePath) + MetadataFileSuffix
+	if err := os.WriteFile(metadataFilePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata file: %w", err)
+	}
 	return nil
 }
 
 func validateAudioFilePath(filePath string) error {
+	_, err := statAudioFile(filePath)
+	return err
+}
+
+// statAudioFile checks that filePath names a regular file and returns its
+// FileInfo.
+func statAudioFile(filePath string) (os.FileInfo, error) {
 	if strings.TrimSpace(filePath) == "" {
-		return fmt.Errorf("audio file path is required")
+		return nil, fmt.Errorf("audio file path is required")
 	}
 
 	info, err := os.Stat(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to access audio file: %w", err)
+		return nil, fmt.Errorf("failed to access audio file: %w", err)
 	}
 	if info.IsDir() {
-		return fmt.Errorf("audio file path is a directory")
+		return nil, fmt.Errorf("audio file path is a directory")
 	}
 
-	return nil
-}
-
-func (s *Server) PlayAudioAsync(filepath string) {
-	go func() {
-		if err := s.PlayAudio(filepath); err != nil {
-			log.Printf("Error playing audio: %v", err)
-		}
-	}()
+	return info, nil
 }
 
-func (s *Server) ReadFileToAudio(filePath string) (string, error) {
+func (s *Server) ReadFileToAudio(ctx context.Context, filePath string, speechOptions SpeechOptions, progress ProgressFunc) (*GeneratedAudio, error) {
 	if strings.TrimSpace(filePath) == "" {
-		return "", fmt.Errorf("file path is required")
+		return nil, fmt.Errorf("file path is required")
 	}
 
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	text := string(content)
-	return s.GenerateAudio(text)
+	return s.GenerateAudio(ctx, text, speechOptions, progress)
 }
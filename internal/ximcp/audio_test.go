@@ -1,10 +1,13 @@
 package ximcp
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/taigrr/elevenlabs/client/types"
 )
 
 func TestGenerateRandomHex(t *testing.T) {
@@ -41,14 +44,15 @@ func TestGenerateRandomHex(t *testing.T) {
 
 func TestGenerateFilePath(t *testing.T) {
 	s := &Server{}
+	directory := t.TempDir()
 
-	path, err := s.generateFilePath()
+	path, err := s.generateFilePath(directory, ".mp3")
 	if err != nil {
 		t.Fatalf("generateFilePath failed: %v", err)
 	}
 
-	if !strings.HasPrefix(path, AudioDirectory+string(filepath.Separator)) {
-		t.Errorf("path should start with %s/, got %q", AudioDirectory, path)
+	if !strings.HasPrefix(path, directory+string(filepath.Separator)) {
+		t.Errorf("path should start with %s/, got %q", directory, path)
 	}
 
 	if !strings.HasSuffix(path, ".mp3") {
@@ -56,7 +60,7 @@ func TestGenerateFilePath(t *testing.T) {
 	}
 
 	// Two paths should differ (different timestamps or random hex)
-	path2, err := s.generateFilePath()
+	path2, err := s.generateFilePath(directory, ".mp3")
 	if err != nil {
 		t.Fatalf("generateFilePath failed: %v", err)
 	}
@@ -134,7 +138,7 @@ func TestWriteTextFile(t *testing.T) {
 func TestReadFileToAudioFileNotFound(t *testing.T) {
 	s := &Server{}
 
-	_, err := s.ReadFileToAudio("/nonexistent/file.txt")
+	_, err := s.ReadFileToAudio(context.Background(), "/nonexistent/file.txt", SpeechOptions{}, nil)
 	if err == nil {
 		t.Error("expected error for nonexistent file")
 	}
@@ -143,7 +147,7 @@ func TestReadFileToAudioFileNotFound(t *testing.T) {
 func TestReadFileToAudioEmptyPath(t *testing.T) {
 	s := &Server{}
 
-	_, err := s.ReadFileToAudio("  ")
+	_, err := s.ReadFileToAudio(context.Background(), "  ", SpeechOptions{}, nil)
 	if err == nil {
 		t.Error("expected error for empty file path")
 	}
@@ -155,7 +159,7 @@ func TestReadFileToAudioEmptyPath(t *testing.T) {
 func TestGenerateAudioEmptyText(t *testing.T) {
 	s := &Server{}
 
-	_, err := s.GenerateAudio(" \t\n ")
+	_, err := s.GenerateAudio(context.Background(), " \t\n ", SpeechOptions{}, nil)
 	if err == nil {
 		t.Error("expected error for empty text")
 	}
@@ -199,7 +203,7 @@ func TestGenerateAudioNoVoice(t *testing.T) {
 		currentVoice: nil,
 	}
 
-	_, err := s.GenerateAudio("test text")
+	_, err := s.GenerateAudio(context.Background(), "test text", SpeechOptions{}, nil)
 	if err == nil {
 		t.Error("expected error when no voice selected")
 	}
@@ -208,3 +212,18 @@ func TestGenerateAudioNoVoice(t *testing.T) {
 		t.Errorf("expected 'no voice selected' error, got: %v", err)
 	}
 }
+
+func TestGenerateAudioInvalidSettings(t *testing.T) {
+	s := &Server{
+		currentVoice: &types.VoiceResponseModel{VoiceID: "abc123", Name: "Alice"},
+	}
+
+	speed := 3.0
+	_, err := s.GenerateAudio(context.Background(), "test text", SpeechOptions{VoiceSettings: VoiceSettings{Speed: &speed}}, nil)
+	if err == nil {
+		t.Fatal("expected error for out-of-range speed")
+	}
+	if !strings.Contains(err.Error(), "speed must be between") {
+		t.Errorf("expected speed range error, got: %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+package ximcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const (
+	DefaultChunkCharacters  = 2500
+	DefaultChunkConcurrency = 2
+	MaxChunkRetries         = 2
+	ChunkRetryDelay         = 500 * time.Millisecond
+)
+
+var (
+	paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+	sentenceEnd    = regexp.MustCompile(`[.!?]+["'”’)\]]*\s+`)
+)
+
+// ProgressFunc is called each time another chunk of a synthesis finishes.
+type ProgressFunc func(completed, total int)
+
+type textSplitter struct {
+	split  func(string) []string
+	joiner string
+}
+
+// textSplitters are tried in order, from the coarsest boundary to the finest.
+var textSplitters = []textSplitter{
+	{split: splitParagraphs, joiner: "\n\n"},
+	{split: splitSentences, joiner: " "},
+	{split: strings.Fields, joiner: " "},
+}
+
+// splitText breaks text into chunks of at most maxChars characters,
+// preferring paragraph, then sentence, then word boundaries.
+func splitText(text string, maxChars int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if maxChars <= 0 {
+		return []string{text}
+	}
+	return splitAtLevel(text, maxChars, 0)
+}
+
+func splitAtLevel(text string, maxChars, level int) []string {
+	if utf8.RuneCountInString(text) <= maxChars {
+		return []string{text}
+	}
+	if level == len(textSplitters) {
+		return splitRunes(text, maxChars)
+	}
+
+	splitter := textSplitters[level]
+	var chunks []string
+	current := ""
+
+	flush := func() {
+		if current != "" {
+			chunks = append(chunks, current)
+			current = ""
+		}
+	}
+
+	for _, piece := range splitter.split(text) {
+		if utf8.RuneCountInString(piece) > maxChars {
+			flush()
+			chunks = append(chunks, splitAtLevel(piece, maxChars, level+1)...)
+			continue
+		}
+
+		candidate := piece
+		if current != "" {
+			candidate = current + splitter.joiner + piece
+		}
+		if utf8.RuneCountInString(candidate) <= maxChars {
+			current = candidate
+			continue
+		}
+
+		flush()
+		current = piece
+	}
+	flush()
+
+	return chunks
+}
+
+func splitParagraphs(text string) []string {
+	var paragraphs []string
+	for _, paragraph := range paragraphBreak.Split(text, -1) {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return paragraphs
+}
+
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(text, -1) {
+		if sentence := strings.TrimSpace(text[start:loc[1]]); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = loc[1]
+	}
+	if rest := strings.TrimSpace(text[start:]); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+func splitRunes(text string, maxChars int) []string {
+	var chunks []string
+	runes := []rune(text)
+	for len(runes) > maxChars {
+		chunks = append(chunks, string(runes[:maxChars]))
+		runes = runes[maxChars:]
+	}
+	if len(runes) > 0 {
+		chunks = append(chunks, string(runes))
+	}
+	return chunks
+}
+
+func (s *Server) maxChunkCharacters() int {
+	if s.chunkCharacters > 0 {
+		return s.chunkCharacters
+	}
+	return DefaultChunkCharacters
+}
+
+func (s *Server) maxChunkConcurrency() int {
+	if s.chunkConcurrency > 0 {
+		return s.chunkConcurrency
+	}
+	return DefaultChunkConcurrency
+}
+
+// chunkStream collects the audio of a single chunk as it downloads. The
+// bytes already handed to the writer are never taken back; a retry only
+// replaces what follows them.
+type chunkStream struct {
+	mutex   sync.Mutex
+	audio   []byte
+	written int
+	done    bool
+	err     error
+	// changed is signalled whenever audio arrives or the download ends.
+	changed chan struct{}
+}
+
+func newChunkStream() *chunkStream {
+	return &chunkStream{changed: make(chan struct{}, 1)}
+}
+
+func (c *chunkStream) Write(p []byte) (int, error) {
+	c.mutex.Lock()
+	c.audio = append(c.audio, p...)
+	c.mutex.Unlock()
+
+	c.notify()
+	return len(p), nil
+}
+
+// restart drops the audio that has not been handed on yet and returns how
+// many bytes of the next attempt to skip.
+func (c *chunkStream) restart() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.audio = c.audio[:c.written]
+	return c.written
+}
+
+func (c *chunkStream) finish(err error) {
+	c.mutex.Lock()
+	c.done = true
+	c.err = err
+	c.mutex.Unlock()
+
+	c.notify()
+}
+
+// take returns the audio that arrived since the last call and whether the
+// download has ended, with its error.
+func (c *chunkStream) take() ([]byte, bool, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	audio := c.audio[c.written:]
+	c.written = len(c.audio)
+	if len(audio) > 0 {
+		return audio, false, nil
+	}
+	return nil, c.done, c.err
+}
+
+func (c *chunkStream) notify() {
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+}
+
+// synthesizeChunks converts each chunk to audio in outputFormat with bounded
+// concurrency and writes the segments to w in their original order. The
+// chunk at the head is written through as its bytes arrive, while the chunks
+// behind it are buffered until their turn, so the writer sees audio as soon
+// as the first frames are downloaded.
+func (s *Server) synthesizeChunks(ctx context.Context, chunks []string, voiceID, modelID, outputFormat string, options types.SynthesisOptions, progress ProgressFunc, w io.Writer) error {
+	var waitGroup sync.WaitGroup
+	defer waitGroup.Wait()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	streams := make([]*chunkStream, len(chunks))
+	for index := range streams {
+		streams[index] = newChunkStream()
+	}
+
+	var (
+		progressMutex sync.Mutex
+		completed     int
+	)
+
+	semaphore := make(chan struct{}, s.maxChunkConcurrency())
+
+	// Slots are acquired in chunk order so the earliest chunks start first.
+	waitGroup.Add(1)
+	go func() {
+		defer waitGroup.Done()
+
+		for index, chunk := range chunks {
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				defer func() { <-semaphore }()
+
+				err := s.synthesizeChunk(ctx, chunk, voiceID, modelID, outputFormat, options, streams[index])
+				streams[index].finish(err)
+				if err != nil {
+					return
+				}
+
+				progressMutex.Lock()
+				defer progressMutex.Unlock()
+
+				completed++
+				if progress != nil {
+					progress(completed, len(chunks))
+				}
+			}()
+		}
+	}()
+
+	for index, stream := range streams {
+		for {
+			audio, done, err := stream.take()
+			if len(audio) > 0 {
+				if _, err := w.Write(audio); err != nil {
+					return fmt.Errorf("chunk %d of %d failed: %w", index+1, len(chunks), err)
+				}
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("chunk %d of %d failed: %w", index+1, len(chunks), err)
+			}
+			if done {
+				break
+			}
+
+			select {
+			case <-stream.changed:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
+// synthesizeChunk downloads the audio for a single chunk into stream. A
+// failure while reading the response is retried like a failed request;
+// the retry resumes after the bytes that were already written on.
+func (s *Server) synthesizeChunk(ctx context.Context, chunk, voiceID, modelID, outputFormat string, options types.SynthesisOptions, stream *chunkStream) error {
+	var lastErr error
+	for attempt := 0; attempt <= MaxChunkRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * ChunkRetryDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := s.downloadChunk(ctx, chunk, voiceID, modelID, outputFormat, options, stream)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", MaxChunkRetries+1, lastErr)
+}
+
+func (s *Server) downloadChunk(ctx context.Context, chunk, voiceID, modelID, outputFormat string, options types.SynthesisOptions, stream *chunkStream) error {
+	skip := stream.restart()
+
+	audioStream, err := s.client.TTSWithFormat(ctx, chunk, voiceID, modelID, outputFormat, options)
+	if err != nil {
+		return err
+	}
+	defer audioStream.Close()
+
+	if _, err := io.CopyN(io.Discard, audioStream, int64(skip)); err != nil {
+		return fmt.Errorf("failed to read audio stream: %w", err)
+	}
+	if _, err := io.Copy(stream, audioStream); err != nil {
+		return fmt.Errorf("failed to read audio stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package ximcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/taigrr/elevenlabs/client"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxChars int
+		expected []string
+	}{
+		{
+			name:     "short text is a single chunk",
+			input:    "Hello world.",
+			maxChars: 100,
+			expected: []string{"Hello world."},
+		},
+		{
+			name:     "empty text has no chunks",
+			input:    "  \n\n ",
+			maxChars: 100,
+			expected: nil,
+		},
+		{
+			name:     "paragraphs packed together",
+			input:    "First paragraph.\n\nSecond paragraph.\n\nThird paragraph.",
+			maxChars: 40,
+			expected: []string{"First paragraph.\n\nSecond paragraph.", "Third paragraph."},
+		},
+		{
+			name:     "long paragraph split on sentences",
+			input:    "One sentence here. Another sentence here! A third one?",
+			maxChars: 40,
+			expected: []string{"One sentence here.", "Another sentence here! A third one?"},
+		},
+		{
+			name:     "long sentence split on words",
+			input:    "alpha beta gamma delta epsilon",
+			maxChars: 12,
+			expected: []string{"alpha beta", "gamma delta", "epsilon"},
+		},
+		{
+			name:     "long word split on characters",
+			input:    "abcdefghij",
+			maxChars: 4,
+			expected: []string{"abcd", "efgh", "ij"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := splitText(tt.input, tt.maxChars)
+			if strings.Join(result, "|") != strings.Join(tt.expected, "|") || len(result) != len(tt.expected) {
+				t.Errorf("splitText(%q, %d) = %q, want %q", tt.input, tt.maxChars, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSplitTextRespectsLimit(t *testing.T) {
+	text := strings.Repeat("Ünïcödé words make a sentence. ", 200)
+
+	for _, chunk := range splitText(text, 100) {
+		if count := utf8.RuneCountInString(chunk); count > 100 {
+			t.Fatalf("chunk exceeds limit with %d characters: %q", count, chunk)
+		}
+	}
+}
+
+// newTTSStandIn returns a server that answers each TTS request with the
+// requested text, failing the first attempts for texts listed in failures.
+func newTTSStandIn(t *testing.T, failures map[string]int) *Server {
+	t.Helper()
+
+	var mutex sync.Mutex
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		mutex.Lock()
+		remaining := failures[body.Text]
+		if remaining > 0 {
+			failures[body.Text] = remaining - 1
+		}
+		mutex.Unlock()
+
+		if remaining != 0 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("[" + body.Text + "]"))
+	}))
+	t.Cleanup(standIn.Close)
+
+	return &Server{client: client.New("test-key").WithEndpoint(standIn.URL)}
+}
+
+func TestSynthesizeChunksPreservesOrder(t *testing.T) {
+	s := newTTSStandIn(t, map[string]int{})
+	s.chunkConcurrency = 3
+
+	chunks := []string{"one", "two", "three", "four", "five"}
+	var progress []int
+	var audioData bytes.Buffer
+	err := s.synthesizeChunks(context.Background(), chunks, "voice", DefaultModelID, DefaultOutputFormat, defaultSynthesisOptions(), func(completed, total int) {
+		if total != len(chunks) {
+			t.Errorf("expected total %d, got %d", len(chunks), total)
+		}
+		progress = append(progress, completed)
+	}, &audioData)
+	if err != nil {
+		t.Fatalf("synthesizeChunks failed: %v", err)
+	}
+
+	if audioData.String() != "[one][two][three][four][five]" {
+		t.Errorf("chunks concatenated out of order: %q", audioData.String())
+	}
+	if len(progress) != len(chunks) || progress[len(progress)-1] != len(chunks) {
+		t.Errorf("expected progress for every chunk, got %v", progress)
+	}
+}
+
+func TestSynthesizeChunksRetries(t *testing.T) {
+	s := newTTSStandIn(t, map[string]int{"two": 1})
+
+	var audioData bytes.Buffer
+	err := s.synthesizeChunks(context.Background(), []string{"one", "two"}, "voice", DefaultModelID, DefaultOutputFormat, defaultSynthesisOptions(), nil, &audioData)
+	if err != nil {
+		t.Fatalf("expected retry to recover, got: %v", err)
+	}
+	if audioData.String() != "[one][two]" {
+		t.Errorf("unexpected audio data: %q", audioData.String())
+	}
+}
+
+func TestSynthesizeChunksRetriesTruncatedAudio(t *testing.T) {
+	var attempts atomic.Int32
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			// Promise more audio than is sent, so the read fails partway.
+			w.Header().Set("Content-Length", "100")
+			_, _ = w.Write([]byte("[who"))
+			return
+		}
+		_, _ = w.Write([]byte("[whole]"))
+	}))
+	defer standIn.Close()
+	s := &Server{client: client.New("test-key").WithEndpoint(standIn.URL)}
+
+	var audioData bytes.Buffer
+	err := s.synthesizeChunks(context.Background(), []string{"one"}, "voice", DefaultModelID, DefaultOutputFormat, defaultSynthesisOptions(), nil, &audioData)
+	if err != nil {
+		t.Fatalf("expected retry to recover, got: %v", err)
+	}
+	// The bytes written before the failure are kept and the retry resumes
+	// after them.
+	if audioData.String() != "[whole]" {
+		t.Errorf("expected the retry to complete the chunk without repeating audio, got %q", audioData.String())
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestSynthesizeChunksIdentifiesFailedChunk(t *testing.T) {
+	s := newTTSStandIn(t, map[string]int{"three": -1})
+
+	err := s.synthesizeChunks(context.Background(), []string{"one", "two", "three"}, "voice", DefaultModelID, DefaultOutputFormat, defaultSynthesisOptions(), nil, io.Discard)
+	if err == nil {
+		t.Fatal("expected error for failing chunk")
+	}
+	if !strings.Contains(err.Error(), "chunk 3 of 3 failed") {
+		t.Errorf("expected error to identify chunk 3, got: %v", err)
+	}
+}
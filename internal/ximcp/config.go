@@ -0,0 +1,59 @@
+package ximcp
+
+import "time"
+
+// Config holds the startup configuration for the server.
+type Config struct {
+	// VoiceID selects the voice used at startup instead of the first one
+	// returned by the API.
+	VoiceID string
+	// ModelID is the default model used for text-to-speech generation.
+	// Empty falls back to the remembered model, then DefaultModelID.
+	ModelID string
+	// VoiceSettings overrides the default synthesis settings. Per-call
+	// settings are applied on top.
+	VoiceSettings VoiceSettings
+	// AudioDirectory is where generated audio is saved. Clients that report
+	// a project root get their own subdirectory. A leading ~ is expanded.
+	AudioDirectory string
+	// OutputFormat is the audio format requested from the API.
+	OutputFormat string
+	// VoiceCacheTTL is how long the voice list is served from cache before it
+	// is refreshed in the background.
+	VoiceCacheTTL time.Duration
+	// StateFile remembers the voice, model, and settings selected at runtime.
+	// It fills in whichever of the values above are unset; empty disables it.
+	StateFile string
+	// Retention limits how many generated clips are kept.
+	Retention RetentionPolicy
+	// ChunkCharacters is the maximum length of a single synthesis request.
+	ChunkCharacters int
+	// ChunkConcurrency bounds how many chunks are synthesized at once.
+	ChunkConcurrency int
+	// AudioOutput selects the playback backend: "speaker", "null", or
+	// "wav:<path>".
+	AudioOutput string
+	// AudioSpeed paces the null and WAV outputs relative to real time.
+	AudioSpeed float64
+	// InlineAudio embeds generated audio in say and read results by default.
+	InlineAudio bool
+	// MaxInlineAudioBytes is the largest clip embedded inline; larger clips
+	// are returned as resource links.
+	MaxInlineAudioBytes int64
+}
+
+// DefaultConfig returns the configuration used when no options are given.
+func DefaultConfig() Config {
+	return Config{
+		AudioDirectory:   DefaultAudioDirectory(),
+		OutputFormat:     DefaultOutputFormat,
+		StateFile:        DefaultStateFile(),
+		VoiceCacheTTL:    DefaultVoiceCacheTTL,
+		ChunkCharacters:  DefaultChunkCharacters,
+		ChunkConcurrency: DefaultChunkConcurrency,
+		AudioOutput:      OutputSpeaker,
+		AudioSpeed:       DefaultOutputSpeed,
+
+		MaxInlineAudioBytes: DefaultMaxInlineAudioBytes,
+	}
+}
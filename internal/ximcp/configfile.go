@@ -0,0 +1,308 @@
+package ximcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const ConfigFileName = "config.json"
+
+// fileConfig mirrors Config as it appears in the config file. Pointer fields
+// distinguish keys that are absent from keys set to their zero value.
+type fileConfig struct {
+	VoiceID             *string        `json:"voice_id"`
+	ModelID             *string        `json:"model_id"`
+	VoiceSettings       *VoiceSettings `json:"voice_settings"`
+	AudioDirectory      *string        `json:"audio_dir"`
+	OutputFormat        *string        `json:"output_format"`
+	StateFile           *string        `json:"state_file"`
+	VoiceCacheTTL       *string        `json:"voice_cache_ttl"`
+	AudioOutput         *string        `json:"audio_output"`
+	AudioSpeed          *float64       `json:"audio_speed"`
+	ChunkCharacters     *int           `json:"chunk_size"`
+	ChunkConcurrency    *int           `json:"chunk_concurrency"`
+	InlineAudio         *bool          `json:"inline_audio"`
+	MaxInlineAudioBytes *int64         `json:"max_inline_audio_bytes"`
+	Retention           *fileRetention `json:"retention"`
+}
+
+type fileRetention struct {
+	MaxFiles *int    `json:"max_files"`
+	MaxAge   *string `json:"max_age"`
+}
+
+// ConfigSearchPaths lists where the config file is looked for, in order,
+// when no path is given: ./elevenlabs-mcp.json, then
+// $XDG_CONFIG_HOME/elevenlabs-mcp/config.json (~/.config when unset).
+func ConfigSearchPaths() []string {
+	paths := []string{DataDirectoryName + ".json"}
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(configHome) {
+		if home, err := os.UserHomeDir(); err == nil {
+			configHome = filepath.Join(home, ".config")
+		}
+	}
+	if configHome != "" {
+		paths = append(paths, filepath.Join(configHome, DataDirectoryName, ConfigFileName))
+	}
+	return paths
+}
+
+// LoadConfigFile applies the config file at path to config. When path is
+// empty the first file found on ConfigSearchPaths is used, and having none
+// is not an error. It returns the path that was loaded, if any.
+func LoadConfigFile(path string, config *Config) (string, error) {
+	if path == "" {
+		for _, candidate := range ConfigSearchPaths() {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+		if path == "" {
+			return "", nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := parseConfigFile(path, data, config); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func parseConfigFile(path string, data []byte, config *Config) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var file fileConfig
+	if err := decoder.Decode(&file); err != nil {
+		return decodeError(path, data, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return configError(path, data, decoder.InputOffset(), "unexpected data after the top-level object")
+	}
+
+	offsets := keyOffsets(data)
+	fail := func(key, format string, args ...any) error {
+		return configError(path, data, offsets[key], fmt.Sprintf(format, args...))
+	}
+
+	if file.VoiceID != nil {
+		config.VoiceID = *file.VoiceID
+	}
+	if file.ModelID != nil {
+		if strings.TrimSpace(*file.ModelID) == "" {
+			return fail("model_id", "model_id must not be empty")
+		}
+		config.ModelID = *file.ModelID
+	}
+
+	if settings := file.VoiceSettings; settings != nil {
+		checks := []struct {
+			key      string
+			settings VoiceSettings
+		}{
+			{"stability", VoiceSettings{Stability: settings.Stability}},
+			{"similarity_boost", VoiceSettings{SimilarityBoost: settings.SimilarityBoost}},
+			{"style", VoiceSettings{Style: settings.Style}},
+			{"speed", VoiceSettings{Speed: settings.Speed}},
+		}
+		for _, check := range checks {
+			if _, err := check.settings.apply(defaultSynthesisOptions()); err != nil {
+				return fail("voice_settings."+check.key, "voice_settings.%v", err)
+			}
+		}
+		config.VoiceSettings = *settings
+	}
+
+	if file.AudioDirectory != nil {
+		config.AudioDirectory = *file.AudioDirectory
+	}
+	if file.OutputFormat != nil {
+		if err := validateOutputFormat(*file.OutputFormat); err != nil {
+			return fail("output_format", "%v", err)
+		}
+		config.OutputFormat = *file.OutputFormat
+	}
+	if file.VoiceCacheTTL != nil {
+		ttl, err := time.ParseDuration(*file.VoiceCacheTTL)
+		if err != nil || ttl <= 0 {
+			return fail("voice_cache_ttl", "voice_cache_ttl must be a positive duration such as \"10m\", got %q", *file.VoiceCacheTTL)
+		}
+		config.VoiceCacheTTL = ttl
+	}
+	if file.StateFile != nil {
+		config.StateFile = *file.StateFile
+	}
+	if file.AudioOutput != nil {
+		if err := validateAudioOutputSpec(*file.AudioOutput); err != nil {
+			return fail("audio_output", "%v", err)
+		}
+		config.AudioOutput = *file.AudioOutput
+	}
+	if file.AudioSpeed != nil {
+		if *file.AudioSpeed <= 0 {
+			return fail("audio_speed", "audio_speed must be positive, got %g", *file.AudioSpeed)
+		}
+		config.AudioSpeed = *file.AudioSpeed
+	}
+	if file.ChunkCharacters != nil {
+		if *file.ChunkCharacters <= 0 {
+			return fail("chunk_size", "chunk_size must be positive, got %d", *file.ChunkCharacters)
+		}
+		config.ChunkCharacters = *file.ChunkCharacters
+	}
+	if file.ChunkConcurrency != nil {
+		if *file.ChunkConcurrency <= 0 {
+			return fail("chunk_concurrency", "chunk_concurrency must be positive, got %d", *file.ChunkConcurrency)
+		}
+		config.ChunkConcurrency = *file.ChunkConcurrency
+	}
+	if file.InlineAudio != nil {
+		config.InlineAudio = *file.InlineAudio
+	}
+	if file.MaxInlineAudioBytes != nil {
+		if *file.MaxInlineAudioBytes <= 0 {
+			return fail("max_inline_audio_bytes", "max_inline_audio_bytes must be positive, got %d", *file.MaxInlineAudioBytes)
+		}
+		config.MaxInlineAudioBytes = *file.MaxInlineAudioBytes
+	}
+
+	if retention := file.Retention; retention != nil {
+		if retention.MaxFiles != nil {
+			if *retention.MaxFiles < 0 {
+				return fail("retention.max_files", "retention.max_files must not be negative, got %d", *retention.MaxFiles)
+			}
+			config.Retention.MaxFiles = *retention.MaxFiles
+		}
+		if retention.MaxAge != nil {
+			maxAge, err := time.ParseDuration(*retention.MaxAge)
+			if err != nil || maxAge < 0 {
+				return fail("retention.max_age", "retention.max_age must be a non-negative duration such as \"720h\", got %q", *retention.MaxAge)
+			}
+			config.Retention.MaxAge = maxAge
+		}
+	}
+
+	return nil
+}
+
+// decodeError reports a JSON decoding error at the line it occurred on.
+func decodeError(path string, data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return configError(path, data, syntaxErr.Offset, syntaxErr.Error())
+	case errors.As(err, &typeErr):
+		return configError(path, data, typeErr.Offset, fmt.Sprintf("%s must be %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value))
+	case errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.EOF):
+		return configError(path, data, int64(len(data)), "unexpected end of file")
+	default:
+		// Unknown fields are only reported once the whole document has been
+		// read, so locate the offending key ourselves.
+		message := strings.TrimPrefix(err.Error(), "json: ")
+		var offset int64
+		if field, ok := strings.CutPrefix(message, "unknown field "); ok {
+			offset = unknownFieldOffset(data, strings.Trim(field, `"`))
+		}
+		return configError(path, data, offset, message)
+	}
+}
+
+// unknownFieldOffset returns the offset of the first key named field, which
+// may be a dotted path or a bare key name.
+func unknownFieldOffset(data []byte, field string) int64 {
+	offsets := keyOffsets(data)
+	if offset, ok := offsets[field]; ok {
+		return offset
+	}
+
+	first := int64(len(data))
+	for key, offset := range offsets {
+		if (key == field || strings.HasSuffix(key, "."+field)) && offset < first {
+			first = offset
+		}
+	}
+	return first
+}
+
+func configError(path string, data []byte, offset int64, message string) error {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	return fmt.Errorf("%s:%d: %s", path, line, message)
+}
+
+// keyOffsets maps each object key in data, as a dotted path such as
+// "voice_settings.stability", to the offset just after it.
+func keyOffsets(data []byte) map[string]int64 {
+	offsets := make(map[string]int64)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	type frame struct {
+		object    bool
+		expectKey bool
+		key       string
+	}
+	var stack []frame
+
+	path := func(key string) string {
+		var parts []string
+		for _, parent := range stack[:len(stack)-1] {
+			if parent.object {
+				parts = append(parts, parent.key)
+			}
+		}
+		return strings.Join(append(parts, key), ".")
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return offsets
+		}
+
+		if len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.object && top.expectKey {
+				if key, ok := token.(string); ok {
+					top.key = key
+					top.expectKey = false
+					offsets[path(key)] = decoder.InputOffset()
+					continue
+				}
+			}
+		}
+
+		switch token {
+		case json.Delim('{'):
+			stack = append(stack, frame{object: true, expectKey: true})
+			continue
+		case json.Delim('['):
+			stack = append(stack, frame{})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		}
+
+		// A value (or a closed container) completes the parent's entry.
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package ximcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ConfigFileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := writeConfigFile(t, `{
+  "voice_id": "abc123",
+  "model_id": "eleven_flash_v2_5",
+  "voice_settings": {"stability": 0.3, "speed": 1.1},
+  "audio_dir": "~/clips",
+  "output_format": "mp3_44100_128",
+  "audio_output": "null",
+  "chunk_size": 1000,
+  "inline_audio": true,
+  "retention": {"max_files": 50, "max_age": "720h"}
+}`)
+
+	config := DefaultConfig()
+	loaded, err := LoadConfigFile(path, &config)
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if loaded != path {
+		t.Errorf("expected loaded path %q, got %q", path, loaded)
+	}
+
+	if config.VoiceID != "abc123" || config.ModelID != "eleven_flash_v2_5" {
+		t.Errorf("unexpected voice or model: %q %q", config.VoiceID, config.ModelID)
+	}
+	if *config.VoiceSettings.Stability != 0.3 || *config.VoiceSettings.Speed != 1.1 || config.VoiceSettings.Style != nil {
+		t.Errorf("unexpected voice settings: %+v", config.VoiceSettings)
+	}
+	if config.AudioDirectory != "~/clips" || config.AudioOutput != "null" || !config.InlineAudio {
+		t.Errorf("unexpected output settings: %+v", config)
+	}
+	if config.ChunkCharacters != 1000 {
+		t.Errorf("expected chunk size 1000, got %d", config.ChunkCharacters)
+	}
+	if config.ChunkConcurrency != DefaultChunkConcurrency {
+		t.Errorf("absent keys should keep defaults, got chunk concurrency %d", config.ChunkConcurrency)
+	}
+	if config.Retention.MaxFiles != 50 || config.Retention.MaxAge != 720*time.Hour {
+		t.Errorf("unexpected retention: %+v", config.Retention)
+	}
+}
+
+func TestLoadConfigFileReportsLineNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "syntax error",
+			content: "{\n  \"model_id\": \"eleven_flash_v2_5\"\n  \"voice_id\": \"abc\"\n}",
+			want:    ":3: invalid character",
+		},
+		{
+			name:    "unknown key",
+			content: "{\n  \"model_id\": \"eleven_flash_v2_5\",\n  \"colour\": \"blue\"\n}",
+			want:    ":3: unknown field \"colour\"",
+		},
+		{
+			name:    "wrong type",
+			content: "{\n  \"voice_id\": \"abc\",\n\n  \"chunk_size\": \"big\"\n}",
+			want:    ":4: chunk_size must be int",
+		},
+		{
+			name:    "out of range setting",
+			content: "{\n  \"voice_settings\": {\n    \"stability\": 0.5,\n    \"speed\": 3\n  }\n}",
+			want:    ":4: voice_settings.speed must be between 0.7 and 1.2",
+		},
+		{
+			name:    "invalid audio output",
+			content: "{\n  \"audio_output\": \"pulse\"\n}",
+			want:    ":2: unknown audio output",
+		},
+		{
+			name:    "invalid retention",
+			content: "{\n  \"retention\": {\n    \"max_age\": \"a month\"\n  }\n}",
+			want:    ":3: retention.max_age must be a non-negative duration",
+		},
+		{
+			name:    "unsupported output format",
+			content: "{\n\n  \"output_format\": \"flac\"\n}",
+			want:    ":3: unsupported output format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfigFile(t, tt.content)
+			config := DefaultConfig()
+			_, err := LoadConfigFile(path, &config)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.HasPrefix(err.Error(), path+":") || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileSearchPath(t *testing.T) {
+	t.Chdir(t.TempDir())
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	config := DefaultConfig()
+	loaded, err := LoadConfigFile("", &config)
+	if err != nil || loaded != "" {
+		t.Fatalf("expected no config file to be found, got %q, %v", loaded, err)
+	}
+
+	xdgPath := filepath.Join(configHome, DataDirectoryName, ConfigFileName)
+	if err := os.MkdirAll(filepath.Dir(xdgPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(xdgPath, []byte(`{"model_id": "from_xdg"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(DataDirectoryName+".json", []byte(`{"model_id": "from_cwd"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err = LoadConfigFile("", &config)
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if loaded != DataDirectoryName+".json" || config.ModelID != "from_cwd" {
+		t.Errorf("expected the working directory file to win, got %q with model %q", loaded, config.ModelID)
+	}
+}
+
+func TestLoadConfigFileMissingExplicitPath(t *testing.T) {
+	config := DefaultConfig()
+	if _, err := LoadConfigFile(filepath.Join(t.TempDir(), "missing.json"), &config); err == nil {
+		t.Error("expected error for a missing explicit config file")
+	}
+}
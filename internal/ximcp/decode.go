@@ -0,0 +1,247 @@
+package ximcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/flac"
+	"github.com/gopxl/beep/v2/mp3"
+	"github.com/gopxl/beep/v2/vorbis"
+	"github.com/gopxl/beep/v2/wav"
+)
+
+const (
+	opusSampleRate = 48000
+	// sniffLength is how much of a file is read to detect its format.
+	sniffLength = 64
+)
+
+// Audio formats recognized by detectAudioFormat.
+const (
+	formatMP3    = "MP3"
+	formatWAV    = "WAV"
+	formatFLAC   = "FLAC"
+	formatVorbis = "Ogg Vorbis"
+	formatOpus   = "Ogg Opus"
+)
+
+var (
+	errUnsupportedAudio = errors.New("unsupported audio format (supported: MP3, WAV, FLAC, and Ogg Vorbis)")
+	errOpusPlayback     = errors.New("Ogg Opus clips are saved but cannot be played (supported: MP3, WAV, FLAC, and Ogg Vorbis); request an mp3, pcm, ulaw, or alaw output_format to hear clips")
+)
+
+// audioDecoder turns encoded audio into a streamer for playback. The
+// streamer owns reader once decoding succeeds.
+type audioDecoder func(reader io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error)
+
+var audioDecoders = map[string]audioDecoder{
+	formatMP3:    decodeMP3,
+	formatWAV:    decodeWAV,
+	formatFLAC:   decodeFLAC,
+	formatVorbis: decodeVorbis,
+}
+
+func decodeMP3(reader io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	streamer, format, err := mp3.Decode(reader)
+	if err != nil {
+		return nil, beep.Format{}, fmt.Errorf("failed to decode mp3: %w", err)
+	}
+	return streamer, format, nil
+}
+
+func decodeWAV(reader io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	streamer, format, err := wav.Decode(reader)
+	if err != nil {
+		return nil, beep.Format{}, fmt.Errorf("failed to decode wav: %w", err)
+	}
+	return streamer, format, nil
+}
+
+func decodeFLAC(reader io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	streamer, format, err := flac.Decode(reader)
+	if err != nil {
+		return nil, beep.Format{}, fmt.Errorf("failed to decode flac: %w", err)
+	}
+	return streamer, format, nil
+}
+
+func decodeVorbis(reader io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+	streamer, format, err := vorbis.Decode(reader)
+	if err != nil {
+		return nil, beep.Format{}, fmt.Errorf("failed to decode ogg vorbis: %w", err)
+	}
+	return streamer, format, nil
+}
+
+// detectAudioFormat identifies audio by the magic bytes at the start of
+// header, returning "" when they are not recognized.
+func detectAudioFormat(header []byte) string {
+	switch {
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return formatWAV
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return formatFLAC
+	case bytes.HasPrefix(header, []byte("OggS")):
+		// The first page holds the codec's identification header.
+		if bytes.Contains(header, []byte("\x01vorbis")) {
+			return formatVorbis
+		}
+		if bytes.Contains(header, []byte("OpusHead")) {
+			return formatOpus
+		}
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return formatMP3
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0 && header[1]&0x06 != 0:
+		// An MPEG audio frame sync; layer 0 would be AAC.
+		return formatMP3
+	}
+	return ""
+}
+
+// fileAudioFormat detects the format of the audio file at filePath.
+func fileAudioFormat(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read audio file: %w", err)
+	}
+	return detectAudioFormat(header[:n]), nil
+}
+
+// fileDecoder returns the decoder for the audio file at filePath, chosen by
+// its contents rather than its extension.
+func fileDecoder(filePath string) (audioDecoder, error) {
+	format, err := fileAudioFormat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if format == formatOpus {
+		return nil, errOpusPlayback
+	}
+
+	decode, ok := audioDecoders[format]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(filePath), errUnsupportedAudio)
+	}
+	return decode, nil
+}
+
+// streamDecoder returns the decoder for audio in format as it arrives from
+// synthesis, before any WAV header has been written. Decoding runs ahead of
+// playback, so the output never waits on the network.
+func streamDecoder(format AudioFormat) (audioDecoder, error) {
+	var decode audioDecoder = decodeMP3
+	switch {
+	case format.isWAV():
+		decode = func(reader io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+			return newPCMStreamer(reader), beep.Format{
+				SampleRate:  beep.SampleRate(format.SampleRate),
+				NumChannels: 1,
+				Precision:   pcmSampleBytes,
+			}, nil
+		}
+	case format.Codec == CodecOpus:
+		return nil, errOpusPlayback
+	}
+
+	return func(reader io.ReadCloser) (beep.StreamSeekCloser, beep.Format, error) {
+		streamer, streamFormat, err := decode(reader)
+		if err != nil {
+			return nil, streamFormat, err
+		}
+		return newPrefetchStreamer(streamer, streamFormat), streamFormat, nil
+	}, nil
+}
+
+// audioDuration measures the playing time of a saved clip.
+func audioDuration(filePath string) (time.Duration, error) {
+	format, err := fileAudioFormat(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	if format == formatOpus {
+		defer file.Close()
+		return oggOpusDuration(file)
+	}
+
+	decode, ok := audioDecoders[format]
+	if !ok {
+		file.Close()
+		return 0, errUnsupportedAudio
+	}
+	streamer, sampleFormat, err := decode(file)
+	if err != nil {
+		file.Close()
+		return 0, err
+	}
+	defer streamer.Close()
+
+	return sampleFormat.SampleRate.D(streamer.Len()), nil
+}
+
+// oggOpusDuration reads the granule positions of an Ogg Opus file. Chunked
+// clips are chained streams, so each stream's final position is added up.
+func oggOpusDuration(r io.Reader) (time.Duration, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, 27)
+
+	var total, granule, preSkip int64
+	for pages := 0; ; pages++ {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			if errors.Is(err, io.EOF) && pages > 0 {
+				break
+			}
+			return 0, fmt.Errorf("failed to read ogg page: %w", err)
+		}
+		if string(header[:4]) != "OggS" {
+			return 0, fmt.Errorf("not an ogg file")
+		}
+
+		segments := make([]byte, header[26])
+		if _, err := io.ReadFull(reader, segments); err != nil {
+			return 0, fmt.Errorf("failed to read ogg page: %w", err)
+		}
+		size := 0
+		for _, segment := range segments {
+			size += int(segment)
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return 0, fmt.Errorf("failed to read ogg page: %w", err)
+		}
+
+		// A beginning-of-stream page carries the OpusHead of a new stream.
+		if header[5]&0x02 != 0 {
+			total += max(granule-preSkip, 0)
+			granule, preSkip = 0, 0
+			if len(payload) >= 12 && string(payload[:8]) == "OpusHead" {
+				preSkip = int64(binary.LittleEndian.Uint16(payload[10:]))
+			}
+		}
+		if position := int64(binary.LittleEndian.Uint64(header[6:])); position >= 0 {
+			granule = position
+		}
+	}
+	total += max(granule-preSkip, 0)
+
+	return time.Duration(total) * time.Second / opusSampleRate, nil
+}
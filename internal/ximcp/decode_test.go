@@ -0,0 +1,132 @@
+package ximcp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// oggPage builds an Ogg page with a single segment holding payload.
+func oggPage(flags byte, granule int64, payload []byte) []byte {
+	page := make([]byte, 27, 28+len(payload))
+	copy(page, "OggS")
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:], uint64(granule))
+	page[26] = 1
+	page = append(page, byte(len(payload)))
+	return append(page, payload...)
+}
+
+func opusHead(preSkip uint16) []byte {
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1
+	head[9] = 1
+	binary.LittleEndian.PutUint16(head[10:], preSkip)
+	return head
+}
+
+// opusStream returns a minimal Ogg Opus stream ending at granule.
+func opusStream(preSkip uint16, granule int64) []byte {
+	var stream bytes.Buffer
+	stream.Write(oggPage(0x02, 0, opusHead(preSkip)))
+	stream.Write(oggPage(0x00, 0, []byte("OpusTags")))
+	stream.Write(oggPage(0x00, -1, []byte{0}))
+	stream.Write(oggPage(0x04, granule, []byte{0}))
+	return stream.Bytes()
+}
+
+func TestOggOpusDuration(t *testing.T) {
+	// Two chained streams, as produced by chunked synthesis.
+	data := append(opusStream(312, 48312), opusStream(312, 24312)...)
+
+	duration, err := oggOpusDuration(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("oggOpusDuration failed: %v", err)
+	}
+	if duration != 1500*time.Millisecond {
+		t.Errorf("expected 1.5s, got %v", duration)
+	}
+
+	if _, err := oggOpusDuration(bytes.NewReader([]byte("ID3 not ogg at all........."))); err == nil {
+		t.Error("expected error for non-Ogg data")
+	}
+}
+
+func TestOpusClipsAreListedButNotPlayable(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "clip.opus")
+	if err := os.WriteFile(filePath, opusStream(0, 96000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	duration, err := audioDuration(filePath)
+	if err != nil || duration != 2*time.Second {
+		t.Errorf("audioDuration = %v, %v; want 2s", duration, err)
+	}
+
+	s := newIdleQueueServer()
+	if _, err := s.EnqueueAudio(filePath, QueueAppend); !errors.Is(err, errOpusPlayback) {
+		t.Errorf("expected opus playback error, got %v", err)
+	}
+
+	format, err := parseOutputFormat("opus_48000_64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := streamDecoder(format); !errors.Is(err, errOpusPlayback) {
+		t.Errorf("expected opus playback error from streamDecoder, got %v", err)
+	}
+}
+
+func TestDetectAudioFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"wav", wavHeader(16000, 0), formatWAV},
+		{"flac", []byte("fLaC\x80\x00\x00\x22"), formatFLAC},
+		{"vorbis", oggPage(0x02, 0, []byte("\x01vorbis\x00\x00\x00\x00")), formatVorbis},
+		{"opus", oggPage(0x02, 0, opusHead(312)), formatOpus},
+		{"id3", []byte("ID3\x04\x00\x00"), formatMP3},
+		{"mpeg frame", []byte{0xFF, 0xFB, 0x90, 0x64}, formatMP3},
+		{"aac frame", []byte{0xFF, 0xF1, 0x50, 0x80}, ""},
+		{"unknown ogg", oggPage(0x02, 0, []byte("\x80theora")), ""},
+		{"text", []byte("hello world"), ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		if got := detectAudioFormat(tt.header); got != tt.want {
+			t.Errorf("%s: detectAudioFormat = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlayDetectsFormatByContent(t *testing.T) {
+	dir := t.TempDir()
+	s := newIdleQueueServer()
+
+	// A WAV file with the wrong extension is still decoded as WAV.
+	misnamed := filepath.Join(dir, "clip.mp3")
+	if err := os.WriteFile(misnamed, encodeClip(AudioFormat{Codec: CodecPCM, SampleRate: 8000}, make([]byte, 16000)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.EnqueueAudio(misnamed, QueueAppend); err != nil {
+		t.Errorf("expected misnamed WAV to queue, got %v", err)
+	}
+	if duration, err := audioDuration(misnamed); err != nil || duration != time.Second {
+		t.Errorf("audioDuration = %v, %v; want 1s", duration, err)
+	}
+
+	text := filepath.Join(dir, "notes.wav")
+	if err := os.WriteFile(text, []byte("not audio at all"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.EnqueueAudio(text, QueueAppend); !errors.Is(err, errUnsupportedAudio) {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+}
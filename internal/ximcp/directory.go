@@ -0,0 +1,181 @@
+package ximcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	DataDirectoryName  = "elevenlabs-mcp"
+	ProjectsDirectory  = "projects"
+	listRootsTimeout   = 2 * time.Second
+	projectHashLength  = 8
+	maxProjectNameSize = 40
+)
+
+var unsafeProjectName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+type audioDirectoryKey struct{}
+
+// DefaultAudioDirectory returns the XDG data directory for generated audio,
+// $XDG_DATA_HOME/elevenlabs-mcp or ~/.local/share/elevenlabs-mcp, falling
+// back to the system temporary directory when there is no home directory.
+func DefaultAudioDirectory() string {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dataHome) {
+		return filepath.Join(dataHome, DataDirectoryName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", DataDirectoryName)
+	}
+	return filepath.Join(os.TempDir(), DataDirectoryName)
+}
+
+// resolveAudioDirectory expands a leading ~ and makes directory absolute, so
+// clips land in the same place no matter where the client launched us.
+func resolveAudioDirectory(directory string) (string, error) {
+	directory = strings.TrimSpace(directory)
+	if directory == "" {
+		directory = DefaultAudioDirectory()
+	}
+
+	if directory == "~" || strings.HasPrefix(directory, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand %s: %w", directory, err)
+		}
+		directory = filepath.Join(home, strings.TrimPrefix(directory, "~"))
+	}
+
+	absolute, err := filepath.Abs(directory)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve audio directory %s: %w", directory, err)
+	}
+	return absolute, nil
+}
+
+// withAudioDirectory scopes the audio directory used by a single request.
+func withAudioDirectory(ctx context.Context, directory string) context.Context {
+	return context.WithValue(ctx, audioDirectoryKey{}, directory)
+}
+
+// audioDirectory returns the directory generated audio is read from and
+// written to for the request carried by ctx.
+func (s *Server) audioDirectory(ctx context.Context) string {
+	if directory, ok := ctx.Value(audioDirectoryKey{}).(string); ok && directory != "" {
+		return directory
+	}
+	return s.baseAudioDirectory()
+}
+
+// baseAudioDirectory falls back to DefaultAudioDirectory for servers
+// constructed without a configured directory.
+func (s *Server) baseAudioDirectory() string {
+	if s.audioRoot != "" {
+		return s.audioRoot
+	}
+	return DefaultAudioDirectory()
+}
+
+// projectContext scopes ctx to the audio directory of the client that sent req.
+func (s *Server) projectContext(ctx context.Context, req *mcp.CallToolRequest) context.Context {
+	if req == nil {
+		return ctx
+	}
+	return withAudioDirectory(ctx, s.sessionAudioDirectory(ctx, req.Session))
+}
+
+// sessionAudioDirectory returns the per-project subdirectory for the first
+// root the client reports, or the base directory when it reports none. The
+// result is cached until the client's roots change or it disconnects.
+func (s *Server) sessionAudioDirectory(ctx context.Context, session *mcp.ServerSession) string {
+	if session == nil {
+		return s.baseAudioDirectory()
+	}
+
+	s.projectsMutex.Lock()
+	directory, ok := s.projectDirectories[session]
+	s.projectsMutex.Unlock()
+	if ok {
+		return directory
+	}
+
+	directory = s.baseAudioDirectory()
+	if root := clientRoot(ctx, session); root != "" {
+		directory = filepath.Join(directory, ProjectsDirectory, projectDirectoryName(root))
+	}
+
+	s.projectsMutex.Lock()
+	defer s.projectsMutex.Unlock()
+
+	if s.projectDirectories == nil {
+		s.projectDirectories = make(map[*mcp.ServerSession]string)
+	}
+	s.projectDirectories[session] = directory
+	return directory
+}
+
+func (s *Server) rootsListChanged(ctx context.Context, req *mcp.RootsListChangedRequest) {
+	s.forgetProjectDirectory(req.Session)
+}
+
+// sessionInitialized drops the session's cached directory once the client
+// disconnects, so closed sessions don't accumulate.
+func (s *Server) sessionInitialized(ctx context.Context, req *mcp.InitializedRequest) {
+	go func() {
+		req.Session.Wait()
+		s.forgetProjectDirectory(req.Session)
+	}()
+}
+
+func (s *Server) forgetProjectDirectory(session *mcp.ServerSession) {
+	s.projectsMutex.Lock()
+	defer s.projectsMutex.Unlock()
+
+	delete(s.projectDirectories, session)
+}
+
+// clientRoot returns the filesystem path of the first file:// root the
+// client reports, or "" if it reports none or does not support roots.
+func clientRoot(ctx context.Context, session *mcp.ServerSession) string {
+	ctx, cancel := context.WithTimeout(ctx, listRootsTimeout)
+	defer cancel()
+
+	result, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		log.Printf("Client roots unavailable, using the base audio directory: %v", err)
+		return ""
+	}
+
+	for _, root := range result.Roots {
+		rootURL, err := url.Parse(root.URI)
+		if err == nil && rootURL.Scheme == "file" && rootURL.Path != "" {
+			return filepath.Clean(rootURL.Path)
+		}
+	}
+	return ""
+}
+
+// projectDirectoryName derives a readable, collision-resistant directory
+// name from a project root, e.g. "my-app-1a2b3c4d".
+func projectDirectoryName(root string) string {
+	name := strings.Trim(unsafeProjectName.ReplaceAllString(filepath.Base(root), "-"), "-.")
+	if len(name) > maxProjectNameSize {
+		name = name[:maxProjectNameSize]
+	}
+	if name == "" {
+		name = "project"
+	}
+
+	sum := sha256.Sum256([]byte(root))
+	return fmt.Sprintf("%s-%x", name, sum[:projectHashLength/2])
+}
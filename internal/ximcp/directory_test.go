@@ -0,0 +1,167 @@
+package ximcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestResolveAudioDirectory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+
+	working := t.TempDir()
+	t.Chdir(working)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", filepath.Join(home, "data", DataDirectoryName)},
+		{"~", home},
+		{"~/clips", filepath.Join(home, "clips")},
+		{"relative/clips", filepath.Join(working, "relative", "clips")},
+		{"/srv/audio", "/srv/audio"},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveAudioDirectory(tt.input)
+		if err != nil {
+			t.Fatalf("resolveAudioDirectory(%q) failed: %v", tt.input, err)
+		}
+		if got != tt.expected {
+			t.Errorf("resolveAudioDirectory(%q) = %q, expected %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestDefaultAudioDirectoryWithoutXDG(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+
+	expected := filepath.Join(home, ".local", "share", DataDirectoryName)
+	if got := DefaultAudioDirectory(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+
+	t.Setenv("HOME", "")
+	expected = filepath.Join(os.TempDir(), DataDirectoryName)
+	if got := DefaultAudioDirectory(); got != expected {
+		t.Errorf("expected %q without a home directory, got %q", expected, got)
+	}
+}
+
+func TestProjectDirectoryName(t *testing.T) {
+	first := projectDirectoryName("/home/user/code/my app")
+	if !strings.HasPrefix(first, "my-app-") {
+		t.Errorf("expected sanitized project name, got %q", first)
+	}
+	if first != projectDirectoryName("/home/user/code/my app") {
+		t.Error("expected project directory names to be stable")
+	}
+	if first == projectDirectoryName("/home/other/my app") {
+		t.Error("expected different roots with the same name to differ")
+	}
+	if name := projectDirectoryName("/"); !strings.HasPrefix(name, "project-") {
+		t.Errorf("expected fallback name for root directory, got %q", name)
+	}
+}
+
+func TestToolsUseClientRootDirectory(t *testing.T) {
+	base := t.TempDir()
+	root := "/home/user/code/my-app"
+	project := filepath.Join(base, ProjectsDirectory, projectDirectoryName(root))
+
+	s := &Server{audioRoot: base}
+	projectCtx := withAudioDirectory(context.Background(), project)
+	if _, err := s.saveAudioFiles(projectCtx, "project clip", []byte("audio"), AudioMetadata{}); err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+	if _, err := s.saveAudioFiles(context.Background(), "base clip", []byte("audio"), AudioMetadata{}); err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+
+	s.mcpServer = mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	s.setupTools()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.mcpServer.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	client.AddRoots(&mcp.Root{URI: "file://" + root, Name: "my-app"})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "history"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	var history HistoryResult
+	decodeStructured(t, result, &history)
+	if len(history.Files) != 1 || history.Files[0].Summary != "project clip" {
+		t.Fatalf("expected only the project clip, got %+v", history.Files)
+	}
+	if _, err := os.Stat(history.Files[0].FilePath); err != nil {
+		t.Errorf("expected history file path to exist: %v", err)
+	}
+}
+
+func TestClosedSessionsForgetProjectDirectory(t *testing.T) {
+	s := &Server{audioRoot: t.TempDir()}
+	s.mcpServer = mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, &mcp.ServerOptions{
+		InitializedHandler: s.sessionInitialized,
+	})
+	s.setupResources()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.mcpServer.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	client.AddRoots(&mcp.Root{URI: "file:///home/user/code/my-app"})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	if _, err := session.ListResources(ctx, nil); err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+
+	cached := func() int {
+		s.projectsMutex.Lock()
+		defer s.projectsMutex.Unlock()
+		return len(s.projectDirectories)
+	}
+	if cached() != 1 {
+		t.Fatalf("expected the session's directory to be cached, got %d entries", cached())
+	}
+
+	session.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for cached() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the closed session's directory to be dropped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,105 @@
+package ximcp
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const DefaultOutputFormat = "mp3_44100_128"
+
+// Codecs of the output formats offered by the API.
+const (
+	CodecMP3  = "mp3"
+	CodecPCM  = "pcm"
+	CodecULaw = "ulaw"
+	CodecALaw = "alaw"
+	CodecOpus = "opus"
+)
+
+// supportedOutputFormats lists the formats the player and history understand.
+var supportedOutputFormats = []string{
+	"mp3_22050_32", "mp3_44100_32", "mp3_44100_64", "mp3_44100_96", DefaultOutputFormat, "mp3_44100_192",
+	"pcm_8000", "pcm_16000", "pcm_22050", "pcm_24000", "pcm_44100", "pcm_48000",
+	"ulaw_8000", "alaw_8000",
+	"opus_48000_32", "opus_48000_64", "opus_48000_96", "opus_48000_128", "opus_48000_192",
+}
+
+// audioExtensions lists the extensions generated clips are saved with.
+var audioExtensions = []string{".mp3", ".wav", ".opus"}
+
+// AudioFormat is a parsed output format such as "pcm_24000". The zero value
+// is treated as MP3.
+type AudioFormat struct {
+	Name       string
+	Codec      string
+	SampleRate int
+}
+
+func validateOutputFormat(format string) error {
+	_, err := parseOutputFormat(format)
+	return err
+}
+
+// parseOutputFormat parses one of supportedOutputFormats; empty selects the
+// default.
+func parseOutputFormat(format string) (AudioFormat, error) {
+	if format == "" {
+		format = DefaultOutputFormat
+	}
+	if !slices.Contains(supportedOutputFormats, format) {
+		return AudioFormat{}, fmt.Errorf("unsupported output format %q (expected %s)", format, strings.Join(supportedOutputFormats, ", "))
+	}
+
+	parts := strings.Split(format, "_")
+	sampleRate, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return AudioFormat{}, fmt.Errorf("invalid sample rate in output format %q", format)
+	}
+	return AudioFormat{Name: format, Codec: parts[0], SampleRate: sampleRate}, nil
+}
+
+// Extension is the file extension clips in this format are saved with. Raw
+// PCM and companded audio are stored as 16-bit WAV files.
+func (f AudioFormat) Extension() string {
+	switch f.Codec {
+	case CodecPCM, CodecULaw, CodecALaw:
+		return ".wav"
+	case CodecOpus:
+		return ".opus"
+	default:
+		return ".mp3"
+	}
+}
+
+// isWAV reports whether the API sends headerless samples that are saved as WAV.
+func (f AudioFormat) isWAV() bool {
+	return f.Extension() == ".wav"
+}
+
+func isAudioFile(name string) bool {
+	return slices.Contains(audioExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// trimAudioExtension strips a clip's audio extension so its sidecar files
+// can be named after it.
+func trimAudioExtension(name string) string {
+	if isAudioFile(name) {
+		return strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name
+}
+
+// audioMIMEType returns the MIME type of the clip called name.
+func audioMIMEType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".wav":
+		return "audio/wav"
+	case ".opus":
+		return "audio/ogg"
+	default:
+		return "audio/mpeg"
+	}
+}
@@ -0,0 +1,130 @@
+package ximcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taigrr/elevenlabs/client"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		input      string
+		codec      string
+		sampleRate int
+		extension  string
+	}{
+		{"", CodecMP3, 44100, ".mp3"},
+		{"mp3_22050_32", CodecMP3, 22050, ".mp3"},
+		{"pcm_24000", CodecPCM, 24000, ".wav"},
+		{"ulaw_8000", CodecULaw, 8000, ".wav"},
+		{"alaw_8000", CodecALaw, 8000, ".wav"},
+		{"opus_48000_64", CodecOpus, 48000, ".opus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			format, err := parseOutputFormat(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if format.Codec != tt.codec || format.SampleRate != tt.sampleRate {
+				t.Errorf("expected %s at %d Hz, got %+v", tt.codec, tt.sampleRate, format)
+			}
+			if ext := format.Extension(); ext != tt.extension {
+				t.Errorf("expected extension %s, got %s", tt.extension, ext)
+			}
+		})
+	}
+
+	if _, err := parseOutputFormat("flac_44100"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+func TestTrimAudioExtension(t *testing.T) {
+	tests := map[string]string{
+		"/clips/1-aaaaa.mp3":  "/clips/1-aaaaa",
+		"/clips/1-aaaaa.wav":  "/clips/1-aaaaa",
+		"/clips/1-aaaaa.opus": "/clips/1-aaaaa",
+		"/clips/notes.txt":    "/clips/notes.txt",
+	}
+	for input, expected := range tests {
+		if got := trimAudioExtension(input); got != expected {
+			t.Errorf("trimAudioExtension(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
+
+func TestAudioMIMEType(t *testing.T) {
+	tests := map[string]string{
+		"clip.mp3":  "audio/mpeg",
+		"clip.wav":  "audio/wav",
+		"clip.opus": "audio/ogg",
+	}
+	for name, expected := range tests {
+		if got := audioMIMEType(name); got != expected {
+			t.Errorf("audioMIMEType(%q) = %q, want %q", name, got, expected)
+		}
+	}
+}
+
+func TestGenerateAudioSavesPCMAsWAV(t *testing.T) {
+	// One second of silence at 8 kHz, split across two responses.
+	var mutex sync.Mutex
+	var requested []string
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v1/text-to-speech/") {
+			http.NotFound(w, r)
+			return
+		}
+		mutex.Lock()
+		requested = append(requested, r.URL.Query().Get("output_format"))
+		mutex.Unlock()
+		_, _ = w.Write(make([]byte, 8000))
+	}))
+	defer standIn.Close()
+
+	s := &Server{
+		client:          client.New("test-key").WithEndpoint(standIn.URL),
+		voices:          []types.VoiceResponseModel{{VoiceID: "abc123", Name: "Alice"}},
+		audioRoot:       t.TempDir(),
+		outputFormat:    DefaultOutputFormat,
+		chunkCharacters: 12,
+	}
+	s.currentVoice = &s.voices[0]
+
+	audio, err := s.GenerateAudio(context.Background(), "First part. Second part.", SpeechOptions{OutputFormat: "pcm_8000"}, nil)
+	if err != nil {
+		t.Fatalf("GenerateAudio failed: %v", err)
+	}
+
+	if len(requested) != 2 || requested[0] != "pcm_8000" {
+		t.Errorf("expected two pcm_8000 requests, got %v", requested)
+	}
+	if filepath.Ext(audio.FilePath) != ".wav" {
+		t.Errorf("expected a .wav clip, got %s", audio.FilePath)
+	}
+	if audio.Duration != time.Second {
+		t.Errorf("expected 1s of audio, got %v", audio.Duration)
+	}
+	if _, err := os.Stat(strings.TrimSuffix(audio.FilePath, ".wav") + ".txt"); err != nil {
+		t.Errorf("expected transcript next to the clip: %v", err)
+	}
+
+	history, err := s.GetAudioHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].OutputFormat != "pcm_8000" || history[0].Duration != time.Second {
+		t.Errorf("expected the WAV clip in history, got %+v", history)
+	}
+}
@@ -1,39 +1,99 @@
 package ximcp
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
+
+	"github.com/taigrr/elevenlabs/client/types"
 )
 
+const MetadataFileSuffix = ".meta.json"
+
 type AudioFile struct {
-	Name    string
-	Summary string
+	Name         string
+	FilePath     string
+	Summary      string
+	Kind         string
+	VoiceID      string
+	VoiceName    string
+	ModelID      string
+	OutputFormat string
+	// SourceFile and SourceVoiceName describe the recording a voice
+	// conversion was made from.
+	SourceFile      string
+	SourceVoiceName string
+	CreatedAt       time.Time
+	Duration        time.Duration
 }
 
-func (s *Server) GetAudioHistory() ([]AudioFile, error) {
-	files, err := os.ReadDir(AudioDirectory)
+// AudioMetadata is stored next to each generated clip and records how it was produced.
+// Kind is empty for speech.
+type AudioMetadata struct {
+	Kind            string                  `json:"kind,omitempty"`
+	VoiceID         string                  `json:"voice_id,omitempty"`
+	VoiceName       string                  `json:"voice_name,omitempty"`
+	ModelID         string                  `json:"model_id,omitempty"`
+	OutputFormat    string                  `json:"output_format,omitempty"`
+	Settings        *types.SynthesisOptions `json:"settings,omitempty"`
+	PromptInfluence *float64                `json:"prompt_influence,omitempty"`
+	SourceFile      string                  `json:"source_file,omitempty"`
+	SourceVoiceID   string                  `json:"source_voice_id,omitempty"`
+	SourceVoiceName string                  `json:"source_voice_name,omitempty"`
+	DurationSeconds float64                 `json:"duration_seconds,omitempty"`
+	CreatedAt       time.Time               `json:"created_at"`
+}
+
+// duration returns the playing time recorded in m, decoding the clip at
+// filePath only for clips saved before it was recorded.
+func (m AudioMetadata) duration(filePath string) time.Duration {
+	if m.DurationSeconds > 0 {
+		return time.Duration(m.DurationSeconds * float64(time.Second))
+	}
+	duration, _ := audioDuration(filePath)
+	return duration
+}
+
+// GetAudioHistory lists the clips in the audio directory for ctx, newest first.
+func (s *Server) GetAudioHistory(ctx context.Context) ([]AudioFile, error) {
+	directory := s.audioDirectory(ctx)
+	files, err := os.ReadDir(directory)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []AudioFile{}, nil
 		}
-		return nil, fmt.Errorf("failed to read %s directory: %w", AudioDirectory, err)
+		return nil, fmt.Errorf("failed to read %s directory: %w", directory, err)
 	}
 
-	return s.processAudioFiles(files), nil
+	return s.processAudioFiles(directory, files), nil
 }
 
-func (s *Server) processAudioFiles(files []os.DirEntry) []AudioFile {
+func (s *Server) processAudioFiles(directory string, files []os.DirEntry) []AudioFile {
 	var audioFiles []AudioFile
 
 	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".mp3") {
-			summary := s.getAudioSummary(file.Name())
+		if !file.IsDir() && isAudioFile(file.Name()) {
+			summary := s.getAudioSummary(directory, file.Name())
+			metadata := s.getAudioMetadata(directory, file.Name())
+			filePath := filepath.Join(directory, file.Name())
 			audioFiles = append(audioFiles, AudioFile{
-				Name:    file.Name(),
-				Summary: summary,
+				Name:            file.Name(),
+				FilePath:        filePath,
+				Summary:         summary,
+				Kind:            metadata.Kind,
+				VoiceID:         metadata.VoiceID,
+				VoiceName:       metadata.VoiceName,
+				ModelID:         metadata.ModelID,
+				OutputFormat:    metadata.OutputFormat,
+				SourceFile:      metadata.SourceFile,
+				SourceVoiceName: metadata.SourceVoiceName,
+				CreatedAt:       metadata.CreatedAt,
+				Duration:        metadata.duration(filePath),
 			})
 		}
 	}
@@ -45,9 +105,9 @@ func (s *Server) processAudioFiles(files []os.DirEntry) []AudioFile {
 	return audioFiles
 }
 
-func (s *Server) getAudioSummary(audioFileName string) string {
-	textFile := strings.TrimSuffix(audioFileName, ".mp3") + ".txt"
-	textPath := filepath.Join(AudioDirectory, textFile)
+func (s *Server) getAudioSummary(directory, audioFileName string) string {
+	textFile := trimAudioExtension(audioFileName) + ".txt"
+	textPath := filepath.Join(directory, textFile)
 
 	content, err := os.ReadFile(textPath)
 	if err != nil {
@@ -57,6 +117,28 @@ func (s *Server) getAudioSummary(audioFileName string) string {
 	return s.createSummary(string(content))
 }
 
+func (s *Server) getAudioMetadata(directory, audioFileName string) AudioMetadata {
+	metadataFile := trimAudioExtension(audioFileName) + MetadataFileSuffix
+	metadataPath := filepath.Join(directory, metadataFile)
+
+	var metadata AudioMetadata
+	content, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return metadata
+	}
+
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		return AudioMetadata{}
+	}
+
+	return metadata
+}
+
+// clipDuration returns the playing time of a saved clip from its metadata.
+func (s *Server) clipDuration(filePath string) time.Duration {
+	return s.getAudioMetadata(filepath.Split(filePath)).duration(filePath)
+}
+
 func (s *Server) createSummary(text string) string {
 	text = strings.TrimSpace(text)
 	words := strings.Fields(text)
@@ -1,9 +1,12 @@
 package ximcp
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestCreateSummary(t *testing.T) {
@@ -57,32 +60,47 @@ func TestCreateSummary(t *testing.T) {
 }
 
 func TestGetAudioHistoryEmptyDir(t *testing.T) {
-	s := &Server{}
+	s := &Server{audioRoot: filepath.Join(t.TempDir(), "does-not-exist")}
 
-	// Temporarily override the audio directory to a non-existent path
-	origDir := AudioDirectory
-	t.Cleanup(func() {
-		// AudioDirectory is a const, so we test the behavior as-is
-		_ = origDir
-	})
+	result, err := s.GetAudioHistory(context.Background())
+	if err != nil {
+		t.Fatalf("GetAudioHistory failed: %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("expected empty result, got %d files", len(result))
+	}
+}
 
-	// GetAudioHistory with non-existent directory should return empty
-	tmpDir := t.TempDir()
-	nonExistent := filepath.Join(tmpDir, "does-not-exist")
+func TestGetAudioHistoryUsesContextDirectory(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, ProjectsDirectory, "app-12345678")
+	s := &Server{audioRoot: base}
+
+	if _, err := s.saveAudioFiles(context.Background(), "base clip", []byte("audio"), AudioMetadata{}); err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+	projectCtx := withAudioDirectory(context.Background(), project)
+	if _, err := s.saveAudioFiles(projectCtx, "project clip", []byte("audio"), AudioMetadata{}); err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
 
-	// We can't override the const, so test processAudioFiles directly
-	entries, err := os.ReadDir(nonExistent)
+	result, err := s.GetAudioHistory(projectCtx)
 	if err != nil {
-		// Expected — directory doesn't exist
-		if !os.IsNotExist(err) {
-			t.Fatalf("unexpected error: %v", err)
-		}
-		return
+		t.Fatalf("GetAudioHistory failed: %v", err)
+	}
+	if len(result) != 1 || result[0].Summary != "project clip" {
+		t.Fatalf("expected only the project clip, got %+v", result)
+	}
+	if filepath.Dir(result[0].FilePath) != project {
+		t.Errorf("expected clip in %s, got %s", project, result[0].FilePath)
 	}
 
-	result := s.processAudioFiles(entries)
-	if len(result) != 0 {
-		t.Errorf("expected empty result, got %d files", len(result))
+	result, err = s.GetAudioHistory(context.Background())
+	if err != nil {
+		t.Fatalf("GetAudioHistory failed: %v", err)
+	}
+	if len(result) != 1 || result[0].Summary != "base clip" {
+		t.Errorf("expected only the base clip, got %+v", result)
 	}
 }
 
@@ -111,7 +129,7 @@ func TestProcessAudioFiles(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	result := s.processAudioFiles(entries)
+	result := s.processAudioFiles(tmpDir, entries)
 
 	if len(result) != 2 {
 		t.Fatalf("expected 2 audio files, got %d", len(result))
@@ -137,15 +155,73 @@ func TestGetAudioSummary(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	// getAudioSummary uses AudioDirectory const, so we test createSummary instead
-	summary := s.createSummary("This is a test summary")
+	summary := s.getAudioSummary(tmpDir, "audio.mp3")
 	if summary != "This is a test summary" {
 		t.Errorf("unexpected summary: %q", summary)
 	}
 
 	// Test with no text file fallback
-	summary = s.createSummary("")
-	if summary != "" {
-		t.Errorf("expected empty summary, got %q", summary)
+	summary = s.getAudioSummary(tmpDir, "missing.mp3")
+	if summary != "(no text summary available)" {
+		t.Errorf("expected fallback summary, got %q", summary)
+	}
+}
+
+func TestWriteMetadataFile(t *testing.T) {
+	s := &Server{}
+
+	tmpDir := t.TempDir()
+	audioPath := filepath.Join(tmpDir, "test.mp3")
+
+	err := s.writeMetadataFile(audioPath, AudioMetadata{VoiceID: "abc123", ModelID: "eleven_multilingual_v2"})
+	if err != nil {
+		t.Fatalf("writeMetadataFile failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tmpDir, "test"+MetadataFileSuffix))
+	if err != nil {
+		t.Fatalf("failed to read metadata file: %v", err)
+	}
+
+	var metadata AudioMetadata
+	if err := json.Unmarshal(content, &metadata); err != nil {
+		t.Fatalf("metadata is not valid JSON: %v", err)
+	}
+
+	if metadata.ModelID != "eleven_multilingual_v2" {
+		t.Errorf("expected model to be recorded, got %q", metadata.ModelID)
+	}
+	if metadata.CreatedAt.IsZero() {
+		t.Error("expected creation time to be recorded")
+	}
+}
+
+func TestHistoryReadsDurationFromMetadata(t *testing.T) {
+	s := &Server{audioRoot: t.TempDir()}
+
+	filePath, err := s.saveAudioFiles(context.Background(), "one second", make([]byte, 16000), AudioMetadata{OutputFormat: "pcm_8000"})
+	if err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+	metadata := s.getAudioMetadata(filepath.Split(filePath))
+	if metadata.DurationSeconds != 1 {
+		t.Fatalf("expected the duration to be recorded at save time, got %v", metadata.DurationSeconds)
+	}
+
+	// A clip that can no longer be decoded still reports its recorded length.
+	if err := os.WriteFile(filePath, []byte("not audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	legacy := filepath.Join(s.audioRoot, "1710000000000-aaaaa.wav")
+	if err := os.WriteFile(legacy, append(wavHeader(8000, 8000), make([]byte, 8000)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := s.GetAudioHistory(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Duration != time.Second || history[1].Duration != 500*time.Millisecond {
+		t.Errorf("unexpected durations %+v", history)
 	}
 }
@@ -0,0 +1,149 @@
+package ximcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const DefaultModelID = "eleven_multilingual_v2"
+
+func (s *Server) refreshModels() error {
+	s.modelsMutex.Lock()
+	defer s.modelsMutex.Unlock()
+
+	models, err := s.client.GetModels(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get models: %w", err)
+	}
+
+	s.models = models
+	return nil
+}
+
+func (s *Server) GetModels() ([]types.ModelResponseModel, string, error) {
+	if err := s.refreshModels(); err != nil {
+		return nil, "", err
+	}
+
+	s.modelsMutex.RLock()
+	defer s.modelsMutex.RUnlock()
+
+	return s.models, s.currentModel, nil
+}
+
+func (s *Server) SetModel(modelID string) (*types.ModelResponseModel, error) {
+	if err := s.ensureModels(); err != nil {
+		return nil, err
+	}
+
+	selectedModel, err := s.selectModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.saveState()
+	return selectedModel, nil
+}
+
+// ensureModels loads the model list unless it is already cached.
+func (s *Server) ensureModels() error {
+	s.modelsMutex.RLock()
+	loaded := len(s.models) > 0
+	s.modelsMutex.RUnlock()
+
+	if loaded {
+		return nil
+	}
+	return s.refreshModels()
+}
+
+func (s *Server) selectModel(modelID string) (*types.ModelResponseModel, error) {
+	s.modelsMutex.Lock()
+	defer s.modelsMutex.Unlock()
+
+	selectedModel, err := s.textToSpeechModel(modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.currentModel = selectedModel.ModelID
+	return selectedModel, nil
+}
+
+// textToSpeechModel looks modelID up in the cached list and checks that it
+// can synthesize speech. The caller must hold modelsMutex.
+func (s *Server) textToSpeechModel(modelID string) (*types.ModelResponseModel, error) {
+	model := s.findModelByID(modelID)
+	if model == nil {
+		return nil, fmt.Errorf("model with ID '%s' not found", modelID)
+	}
+
+	if !model.CanDoTextToSpeech {
+		return nil, fmt.Errorf("model '%s' does not support text-to-speech", modelID)
+	}
+	return model, nil
+}
+
+func (s *Server) findModelByID(modelID string) *types.ModelResponseModel {
+	for i, model := range s.models {
+		if model.ModelID == modelID {
+			return &s.models[i]
+		}
+	}
+	return nil
+}
+
+// resolveModelID returns the per-call override, validated like SetModel, or
+// the current model.
+func (s *Server) resolveModelID(override string) (string, error) {
+	if override = strings.TrimSpace(override); override != "" {
+		if err := s.ensureModels(); err != nil {
+			return "", err
+		}
+
+		s.modelsMutex.RLock()
+		defer s.modelsMutex.RUnlock()
+
+		model, err := s.textToSpeechModel(override)
+		if err != nil {
+			return "", err
+		}
+		return model.ModelID, nil
+	}
+
+	s.modelsMutex.RLock()
+	defer s.modelsMutex.RUnlock()
+
+	if s.currentModel != "" {
+		return s.currentModel, nil
+	}
+	return DefaultModelID, nil
+}
+
+func (s *Server) formatModelList(models []types.ModelResponseModel, currentModelID string) string {
+	var modelList strings.Builder
+	modelList.WriteString("Available models:\n")
+
+	for _, model := range models {
+		if !model.CanDoTextToSpeech {
+			continue
+		}
+		marker := "  "
+		if model.ModelID == currentModelID {
+			marker = "* "
+		}
+		modelList.WriteString(fmt.Sprintf("%s%s (%s) - %d languages\n",
+			marker, model.Name, model.ModelID, len(model.Languages)))
+	}
+
+	if currentModelID != "" {
+		modelList.WriteString(fmt.Sprintf("\nCurrently selected: %s", currentModelID))
+	} else {
+		modelList.WriteString("\nNo model currently selected")
+	}
+
+	return modelList.String()
+}
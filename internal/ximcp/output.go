@@ -0,0 +1,234 @@
+package ximcp
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/speaker"
+	"github.com/gopxl/beep/v2/wav"
+)
+
+const (
+	OutputSpeaker = "speaker"
+	OutputNull    = "null"
+	OutputWAV     = "wav"
+
+	DefaultOutputSpeed = 1.0
+	outputBufferPeriod = time.Second / 10
+)
+
+// AudioOutput is a playback backend that mixes streamers into a device or sink.
+type AudioOutput interface {
+	// Play starts playing streamer and returns immediately.
+	Play(streamer beep.Streamer)
+	// Lock stops the output from pulling samples so that playing streamers
+	// can be modified safely. It must be held only briefly.
+	Lock()
+	Unlock()
+	// Close stops the output and flushes any sink it writes to.
+	Close() error
+}
+
+// newAudioOutput creates the backend described by spec, which is "speaker",
+// "null", or "wav:<path>". speed paces the null and WAV sinks relative to
+// real time. When the speaker cannot be opened, playback falls back to the
+// null sink so the server still runs on machines without audio devices.
+func newAudioOutput(spec string, speed float64) (AudioOutput, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("audio output speed must be positive, got %g", speed)
+	}
+
+	kind, path, err := parseAudioOutputSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case OutputSpeaker:
+		output, err := newSpeakerOutput()
+		if err != nil {
+			log.Printf("Warning: %v; falling back to the null audio output", err)
+			return newNullOutput(speed), nil
+		}
+		return output, nil
+	case OutputWAV:
+		return newWAVOutput(path, speed)
+	default:
+		return newNullOutput(speed), nil
+	}
+}
+
+// parseAudioOutputSpec splits an output spec into its kind and, for the WAV
+// sink, the path to record to.
+func parseAudioOutputSpec(spec string) (string, string, error) {
+	kind, path, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch kind = strings.ToLower(kind); kind {
+	case "", OutputSpeaker:
+		return OutputSpeaker, "", nil
+	case OutputNull:
+		return OutputNull, "", nil
+	case OutputWAV:
+		if strings.TrimSpace(path) == "" {
+			return "", "", fmt.Errorf("wav audio output requires a path, e.g. wav:/tmp/out.wav")
+		}
+		return OutputWAV, path, nil
+	default:
+		return "", "", fmt.Errorf("unknown audio output %q (expected speaker, null, or wav:<path>)", spec)
+	}
+}
+
+func validateAudioOutputSpec(spec string) error {
+	_, _, err := parseAudioOutputSpec(spec)
+	return err
+}
+
+// speakerOutput plays through the system audio device.
+type speakerOutput struct{}
+
+func newSpeakerOutput() (*speakerOutput, error) {
+	sampleRate := beep.SampleRate(AudioSampleRate)
+	if err := speaker.Init(sampleRate, sampleRate.N(outputBufferPeriod)); err != nil {
+		return nil, fmt.Errorf("failed to initialize speaker: %w", err)
+	}
+	return &speakerOutput{}, nil
+}
+
+func (o *speakerOutput) Play(streamer beep.Streamer) { speaker.Play(streamer) }
+func (o *speakerOutput) Lock()                       { speaker.Lock() }
+func (o *speakerOutput) Unlock()                     { speaker.Unlock() }
+
+func (o *speakerOutput) Close() error {
+	speaker.Close()
+	return nil
+}
+
+// pacedMixer mixes playing streamers and consumes them no faster than speed
+// times real time. It blocks while nothing is playing and stops once closed.
+type pacedMixer struct {
+	mutex  sync.Mutex
+	mixer  beep.Mixer
+	speed  float64
+	wake   chan struct{}
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newPacedMixer(speed float64) *pacedMixer {
+	paced := &pacedMixer{
+		speed:  speed,
+		wake:   make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	paced.mixer.KeepAlive(false)
+	return paced
+}
+
+func (m *pacedMixer) Play(streamer beep.Streamer) {
+	m.mutex.Lock()
+	m.mixer.Add(streamer)
+	m.mutex.Unlock()
+
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (m *pacedMixer) Lock()   { m.mutex.Lock() }
+func (m *pacedMixer) Unlock() { m.mutex.Unlock() }
+
+func (m *pacedMixer) Close() error {
+	m.once.Do(func() { close(m.closed) })
+	return nil
+}
+
+func (m *pacedMixer) Stream(samples [][2]float64) (int, bool) {
+	for {
+		m.mutex.Lock()
+		if m.mixer.Len() > 0 {
+			n, _ := m.mixer.Stream(samples)
+			m.mutex.Unlock()
+
+			// Samples already mixed are returned even when closing, so sinks
+			// record everything that was played.
+			period := beep.SampleRate(AudioSampleRate).D(n)
+			select {
+			case <-time.After(time.Duration(float64(period) / m.speed)):
+			case <-m.closed:
+			}
+			return n, true
+		}
+		m.mutex.Unlock()
+
+		select {
+		case <-m.wake:
+		case <-m.closed:
+			return 0, false
+		}
+	}
+}
+
+func (m *pacedMixer) Err() error { return nil }
+
+// nullOutput discards samples, which keeps playback timing and controls
+// working on machines without an audio device.
+type nullOutput struct {
+	*pacedMixer
+}
+
+func newNullOutput(speed float64) *nullOutput {
+	output := &nullOutput{pacedMixer: newPacedMixer(speed)}
+	go func() {
+		samples := make([][2]float64, beep.SampleRate(AudioSampleRate).N(outputBufferPeriod))
+		for {
+			if _, ok := output.Stream(samples); !ok {
+				return
+			}
+		}
+	}()
+	return output
+}
+
+// wavOutput records everything played into a WAV file, which is finalized
+// when the output is closed.
+type wavOutput struct {
+	*pacedMixer
+	file *os.File
+	done chan error
+}
+
+func newWAVOutput(path string, speed float64) (*wavOutput, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create wav output: %w", err)
+	}
+
+	output := &wavOutput{
+		pacedMixer: newPacedMixer(speed),
+		file:       file,
+		done:       make(chan error, 1),
+	}
+	format := beep.Format{SampleRate: AudioSampleRate, NumChannels: 2, Precision: 2}
+	go func() {
+		output.done <- wav.Encode(file, output.pacedMixer, format)
+	}()
+	return output, nil
+}
+
+func (o *wavOutput) Close() error {
+	o.pacedMixer.Close()
+	encodeErr := <-o.done
+	closeErr := o.file.Close()
+	if encodeErr != nil {
+		return fmt.Errorf("failed to encode wav output: %w", encodeErr)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to close wav output: %w", closeErr)
+	}
+	return nil
+}
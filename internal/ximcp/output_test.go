@@ -0,0 +1,92 @@
+package ximcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/gopxl/beep/v2/wav"
+)
+
+func playToEnd(t *testing.T, output AudioOutput, duration time.Duration) {
+	t.Helper()
+
+	done := make(chan struct{})
+	output.Play(beep.Seq(newSilentStreamer(duration), beep.Callback(func() {
+		close(done)
+	})))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("clip did not finish playing")
+	}
+}
+
+func TestNewAudioOutputRejectsInvalidSpecs(t *testing.T) {
+	tests := []struct {
+		spec  string
+		speed float64
+		want  string
+	}{
+		{spec: "null", speed: 0, want: "must be positive"},
+		{spec: "wav:", speed: 1, want: "requires a path"},
+		{spec: "pulse", speed: 1, want: "unknown audio output"},
+	}
+
+	for _, test := range tests {
+		_, err := newAudioOutput(test.spec, test.speed)
+		if err == nil {
+			t.Errorf("expected error for %q", test.spec)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.want) {
+			t.Errorf("expected %q error for %q, got %v", test.want, test.spec, err)
+		}
+	}
+}
+
+func TestNullOutputPlaysClip(t *testing.T) {
+	output, err := newAudioOutput("null", 100)
+	if err != nil {
+		t.Fatalf("newAudioOutput failed: %v", err)
+	}
+	defer output.Close()
+
+	playToEnd(t, output, time.Second)
+}
+
+func TestWAVOutputRecordsPlayback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.wav")
+	output, err := newAudioOutput("wav:"+path, 100)
+	if err != nil {
+		t.Fatalf("newAudioOutput failed: %v", err)
+	}
+
+	playToEnd(t, output, time.Second)
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open recording: %v", err)
+	}
+	defer file.Close()
+
+	streamer, format, err := wav.Decode(file)
+	if err != nil {
+		t.Fatalf("recording is not a valid wav file: %v", err)
+	}
+	defer streamer.Close()
+
+	if format.SampleRate != AudioSampleRate {
+		t.Errorf("expected sample rate %d, got %d", AudioSampleRate, format.SampleRate)
+	}
+	if want := format.SampleRate.N(time.Second); streamer.Len() != want {
+		t.Errorf("expected %d recorded samples, got %d", want, streamer.Len())
+	}
+}
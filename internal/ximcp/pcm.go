@@ -0,0 +1,196 @@
+package ximcp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// PCM output formats are 16-bit little-endian mono samples.
+const (
+	wavHeaderSize  = 44
+	pcmSampleBytes = 2
+)
+
+// wavHeader returns the header of a mono 16-bit WAV file holding dataBytes of
+// samples.
+func wavHeader(sampleRate int, dataBytes int64) []byte {
+	dataBytes = min(dataBytes, math.MaxUint32-wavHeaderSize+8)
+
+	header := make([]byte, wavHeaderSize)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(dataBytes+wavHeaderSize-8))
+	copy(header[8:], "WAVE")
+	copy(header[12:], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], 1)
+	binary.LittleEndian.PutUint16(header[22:], 1)
+	binary.LittleEndian.PutUint32(header[24:], uint32(sampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(sampleRate*pcmSampleBytes))
+	binary.LittleEndian.PutUint16(header[32:], pcmSampleBytes)
+	binary.LittleEndian.PutUint16(header[34:], 8*pcmSampleBytes)
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(dataBytes))
+	return header
+}
+
+// encodeClip returns the saved form of audio in format, adding a WAV header
+// to PCM samples.
+func encodeClip(format AudioFormat, audioData []byte) []byte {
+	if !format.isWAV() {
+		return audioData
+	}
+	return append(wavHeader(format.SampleRate, int64(len(audioData))), audioData...)
+}
+
+// clipWriter saves audio in format to file. WAV clips get a placeholder
+// header that is completed on Close, once the length is known.
+type clipWriter struct {
+	file      *os.File
+	format    AudioFormat
+	dataBytes int64
+}
+
+func newClipWriter(file *os.File, format AudioFormat) (*clipWriter, error) {
+	if format.isWAV() {
+		if _, err := file.Write(wavHeader(format.SampleRate, 0)); err != nil {
+			return nil, fmt.Errorf("failed to write audio file: %w", err)
+		}
+	}
+	return &clipWriter{file: file, format: format}, nil
+}
+
+func (w *clipWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.dataBytes += int64(n)
+	return n, err
+}
+
+// Close completes the WAV header, if any, and closes the file.
+func (w *clipWriter) Close() error {
+	var err error
+	if w.format.isWAV() {
+		_, err = w.file.WriteAt(wavHeader(w.format.SampleRate, w.dataBytes), 0)
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// sampleConverter expands µ-law and A-law samples to 16-bit PCM.
+type sampleConverter struct {
+	w      io.Writer
+	decode func(byte) int16
+}
+
+// newSampleConverter returns a writer that passes audio in format on to w as
+// it should be saved: companded samples become 16-bit PCM, and everything
+// else is passed through unchanged.
+func newSampleConverter(w io.Writer, format AudioFormat) io.Writer {
+	switch format.Codec {
+	case CodecULaw:
+		return &sampleConverter{w: w, decode: ulawToLinear}
+	case CodecALaw:
+		return &sampleConverter{w: w, decode: alawToLinear}
+	default:
+		return w
+	}
+}
+
+func (c *sampleConverter) Write(p []byte) (int, error) {
+	samples := make([]byte, len(p)*pcmSampleBytes)
+	for index, sample := range p {
+		binary.LittleEndian.PutUint16(samples[index*pcmSampleBytes:], uint16(c.decode(sample)))
+	}
+	if _, err := c.w.Write(samples); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// ulawToLinear decodes a G.711 µ-law sample.
+func ulawToLinear(sample byte) int16 {
+	sample = ^sample
+	magnitude := (int(sample&0x0F)<<3 + 0x84) << ((sample & 0x70) >> 4)
+	if sample&0x80 != 0 {
+		return int16(0x84 - magnitude)
+	}
+	return int16(magnitude - 0x84)
+}
+
+// alawToLinear decodes a G.711 A-law sample.
+func alawToLinear(sample byte) int16 {
+	sample ^= 0x55
+	magnitude := int(sample&0x0F) << 4
+	switch segment := (sample & 0x70) >> 4; segment {
+	case 0:
+		magnitude += 8
+	case 1:
+		magnitude += 0x108
+	default:
+		magnitude = (magnitude + 0x108) << (segment - 1)
+	}
+	if sample&0x80 != 0 {
+		return int16(magnitude)
+	}
+	return int16(-magnitude)
+}
+
+// pcmStreamer plays headerless 16-bit mono PCM that may still be arriving.
+// Its length is unknown and it cannot seek.
+type pcmStreamer struct {
+	source   io.ReadCloser
+	reader   *bufio.Reader
+	buffer   []byte
+	position int
+	done     bool
+	err      error
+}
+
+func newPCMStreamer(source io.ReadCloser) *pcmStreamer {
+	return &pcmStreamer{source: source, reader: bufio.NewReader(source)}
+}
+
+func (p *pcmStreamer) Stream(samples [][2]float64) (int, bool) {
+	if p.done {
+		return 0, false
+	}
+
+	if need := len(samples) * pcmSampleBytes; cap(p.buffer) < need {
+		p.buffer = make([]byte, need)
+	}
+	buffer := p.buffer[:len(samples)*pcmSampleBytes]
+
+	n, err := io.ReadFull(p.reader, buffer)
+	if err != nil {
+		p.done = true
+		if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			p.err = err
+		}
+	}
+
+	count := n / pcmSampleBytes
+	for index := range count {
+		value := float64(int16(binary.LittleEndian.Uint16(buffer[index*pcmSampleBytes:]))) / (1 << 15)
+		samples[index] = [2]float64{value, value}
+	}
+	p.position += count
+	return count, count > 0
+}
+
+func (p *pcmStreamer) Err() error    { return p.err }
+func (p *pcmStreamer) Len() int      { return 0 }
+func (p *pcmStreamer) Position() int { return p.position }
+
+func (p *pcmStreamer) Seek(int) error {
+	return errors.New("cannot seek streamed PCM audio")
+}
+
+func (p *pcmStreamer) Close() error {
+	return p.source.Close()
+}
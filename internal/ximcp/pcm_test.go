@@ -0,0 +1,119 @@
+package ximcp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gopxl/beep/v2/wav"
+)
+
+func TestCompandedSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		decode func(byte) int16
+		input  byte
+		want   int16
+	}{
+		{"ulaw silence", ulawToLinear, 0xFF, 0},
+		{"ulaw negative peak", ulawToLinear, 0x00, -32124},
+		{"ulaw positive peak", ulawToLinear, 0x80, 32124},
+		{"alaw smallest positive", alawToLinear, 0xD5, 8},
+		{"alaw smallest negative", alawToLinear, 0x55, -8},
+		{"alaw positive peak", alawToLinear, 0xAA, 32256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.decode(tt.input); got != tt.want {
+				t.Errorf("decode(%#x) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSampleConverterExpandsULaw(t *testing.T) {
+	var output bytes.Buffer
+	converter := newSampleConverter(&output, AudioFormat{Codec: CodecULaw, SampleRate: 8000})
+
+	n, err := converter.Write([]byte{0xFF, 0x80})
+	if err != nil || n != 2 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if got := int16(binary.LittleEndian.Uint16(output.Bytes()[2:])); output.Len() != 4 || got != 32124 {
+		t.Errorf("expected two 16-bit samples, got %x", output.Bytes())
+	}
+
+	var passthrough bytes.Buffer
+	if newSampleConverter(&passthrough, AudioFormat{Codec: CodecPCM}) != io.Writer(&passthrough) {
+		t.Error("expected PCM to be written unchanged")
+	}
+}
+
+func TestClipWriterCompletesWAVHeader(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "clip.wav")
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clip, err := newClipWriter(file, AudioFormat{Codec: CodecPCM, SampleRate: 16000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		if _, err := clip.Write(make([]byte, 4000)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := clip.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := os.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamer, format, err := wav.Decode(reopened)
+	if err != nil {
+		t.Fatalf("saved clip is not a valid WAV file: %v", err)
+	}
+	defer streamer.Close()
+
+	if format.SampleRate != 16000 || format.NumChannels != 1 {
+		t.Errorf("unexpected format %+v", format)
+	}
+	if streamer.Len() != 8000 {
+		t.Errorf("expected 8000 samples, got %d", streamer.Len())
+	}
+}
+
+func TestPCMStreamerReadsUntilEOF(t *testing.T) {
+	var samples bytes.Buffer
+	for _, value := range []int16{0, 16384, -16384} {
+		binary.Write(&samples, binary.LittleEndian, value)
+	}
+	// A trailing odd byte is not a whole sample.
+	samples.WriteByte(0x01)
+
+	streamer := newPCMStreamer(io.NopCloser(&samples))
+	defer streamer.Close()
+
+	buffer := make([][2]float64, 8)
+	n, ok := streamer.Stream(buffer)
+	if !ok || n != 3 {
+		t.Fatalf("Stream = %d, %v; want 3 samples", n, ok)
+	}
+	if buffer[1][0] != 0.5 || buffer[2][1] != -0.5 {
+		t.Errorf("unexpected samples %v", buffer[:n])
+	}
+	if n, ok := streamer.Stream(buffer); ok || n != 0 {
+		t.Errorf("expected end of stream, got %d, %v", n, ok)
+	}
+	if streamer.Err() != nil || streamer.Position() != 3 {
+		t.Errorf("unexpected state: err %v, position %d", streamer.Err(), streamer.Position())
+	}
+}
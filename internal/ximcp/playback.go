@@ -0,0 +1,254 @@
+package ximcp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+var (
+	errNothingPlaying  = errors.New("nothing is playing")
+	errPlaybackStopped = errors.New("playback stopped")
+)
+
+// playback tracks the clip currently being played and lets it be paused or
+// stopped from another goroutine. It is created as soon as the player takes
+// an entry off the queue, before the audio has been decoded. Its mutable
+// fields are guarded by the output lock, except source, which stop closes
+// before taking that lock.
+type playback struct {
+	entry       QueueEntry
+	output      AudioOutput
+	ctrl        *beep.Ctrl
+	streamer    beep.StreamSeeker
+	format      beep.Format
+	source      io.Closer
+	sourceMutex sync.Mutex
+	done        chan struct{}
+	stopped     chan struct{}
+	stopOnce    sync.Once
+}
+
+// PlaybackStatus describes the clip currently being played.
+type PlaybackStatus struct {
+	ID       uint64
+	Name     string
+	Position time.Duration
+	Duration time.Duration
+	Paused   bool
+}
+
+func newPlayback(entry QueueEntry, output AudioOutput) *playback {
+	return &playback{
+		entry:   entry,
+		output:  output,
+		ctrl:    &beep.Ctrl{},
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+func (p *playback) isStopped() bool {
+	select {
+	case <-p.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
+// setSource records the opened audio so stop can close it. It reports false
+// when the playback was stopped before the audio was opened.
+func (p *playback) setSource(source io.Closer) bool {
+	p.sourceMutex.Lock()
+	defer p.sourceMutex.Unlock()
+
+	if p.isStopped() {
+		return false
+	}
+
+	p.source = source
+	return true
+}
+
+// attach starts feeding the decoded streamer through the controller. It
+// reports false when the playback was stopped while the audio was decoding.
+func (p *playback) attach(streamer beep.StreamSeeker, format beep.Format) bool {
+	p.output.Lock()
+	defer p.output.Unlock()
+
+	if p.isStopped() {
+		return false
+	}
+
+	p.ctrl.Streamer = beep.Resample(4, format.SampleRate, AudioSampleRate, streamer)
+	p.streamer = streamer
+	p.format = format
+	return true
+}
+
+// stop closes the source, detaches the streamer so the output finishes it
+// on its next pull, and releases anyone waiting on the playback.
+func (p *playback) stop() {
+	p.stopOnce.Do(func() {
+		// The source is closed first so a decoder still waiting on streamed
+		// audio gives up without the output lock being held.
+		p.sourceMutex.Lock()
+		close(p.stopped)
+		source := p.source
+		p.sourceMutex.Unlock()
+		if source != nil {
+			source.Close()
+		}
+
+		p.output.Lock()
+		p.ctrl.Streamer = nil
+		p.output.Unlock()
+	})
+}
+
+func (p *playback) setPaused(paused bool) error {
+	p.output.Lock()
+	defer p.output.Unlock()
+
+	if p.isStopped() {
+		return errNothingPlaying
+	}
+	if p.ctrl.Paused == paused {
+		if paused {
+			return fmt.Errorf("%s is already paused", p.entry.Name)
+		}
+		return fmt.Errorf("%s is not paused", p.entry.Name)
+	}
+
+	p.ctrl.Paused = paused
+	return nil
+}
+
+func (p *playback) status() PlaybackStatus {
+	p.output.Lock()
+	defer p.output.Unlock()
+
+	status := PlaybackStatus{
+		ID:     p.entry.ID,
+		Name:   p.entry.Name,
+		Paused: p.ctrl.Paused,
+	}
+	if p.streamer == nil {
+		return status
+	}
+
+	status.Position = p.format.SampleRate.D(p.streamer.Position())
+	// Streams decoded while still downloading have no known length.
+	if length := p.streamer.Len(); length > 0 {
+		status.Duration = p.format.SampleRate.D(length)
+	}
+	return status
+}
+
+// playStreamer plays streamer through current and blocks until it finishes
+// or is stopped.
+func (s *Server) playStreamer(current *playback, streamer beep.StreamSeeker, format beep.Format) error {
+	if !current.attach(streamer, format) {
+		return errPlaybackStopped
+	}
+
+	current.output.Play(beep.Seq(current.ctrl, beep.Callback(func() {
+		close(current.done)
+	})))
+
+	select {
+	case <-current.done:
+	case <-current.stopped:
+	}
+	return nil
+}
+
+func (s *Server) activePlayback() *playback {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+
+	return s.currentPlayback
+}
+
+// StopPlayback stops the current clip and clears every clip waiting to play.
+func (s *Server) StopPlayback() (string, error) {
+	s.ClearQueue()
+
+	current := s.activePlayback()
+	if current == nil {
+		return "", errNothingPlaying
+	}
+
+	current.stop()
+	return current.entry.Name, nil
+}
+
+// SkipPlayback stops the current clip and lets the next queued clip start.
+func (s *Server) SkipPlayback() (string, error) {
+	current := s.activePlayback()
+	if current == nil {
+		return "", errNothingPlaying
+	}
+
+	current.stop()
+	return current.entry.Name, nil
+}
+
+func (s *Server) PausePlayback() (string, error) {
+	current := s.activePlayback()
+	if current == nil {
+		return "", errNothingPlaying
+	}
+
+	if err := current.setPaused(true); err != nil {
+		return "", err
+	}
+	return current.entry.Name, nil
+}
+
+func (s *Server) ResumePlayback() (string, error) {
+	current := s.activePlayback()
+	if current == nil {
+		return "", errNothingPlaying
+	}
+
+	if err := current.setPaused(false); err != nil {
+		return "", err
+	}
+	return current.entry.Name, nil
+}
+
+func (s *Server) PlaybackStatus() (*PlaybackStatus, error) {
+	current := s.activePlayback()
+	if current == nil {
+		return nil, errNothingPlaying
+	}
+
+	status := current.status()
+	return &status, nil
+}
+
+func formatPlaybackStatus(status *PlaybackStatus) string {
+	var statusText strings.Builder
+
+	state := "Playing"
+	if status.Paused {
+		state = "Paused"
+	}
+	statusText.WriteString(fmt.Sprintf("%s: %s\n", state, status.Name))
+
+	position := status.Position.Round(time.Second)
+	if status.Duration > 0 {
+		statusText.WriteString(fmt.Sprintf("Position: %s / %s", position, status.Duration.Round(time.Second)))
+	} else {
+		statusText.WriteString(fmt.Sprintf("Position: %s (duration unknown while streaming)", position))
+	}
+
+	return statusText.String()
+}
@@ -0,0 +1,189 @@
+package ximcp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+var testFormat = beep.Format{SampleRate: AudioSampleRate, NumChannels: 2, Precision: 2}
+
+func newSilentStreamer(duration time.Duration) beep.StreamSeeker {
+	buffer := beep.NewBuffer(testFormat)
+	buffer.Append(beep.Silence(testFormat.SampleRate.N(duration)))
+	return buffer.Streamer(0, buffer.Len())
+}
+
+// newIdleOutput returns an output that nothing pulls samples from, so clips
+// played through it only end when they are stopped.
+func newIdleOutput() AudioOutput {
+	return newPacedMixer(DefaultOutputSpeed)
+}
+
+// startPlayback makes a clip the current playback and runs playStreamer in
+// the background.
+func startPlayback(t *testing.T, s *Server, name string) chan error {
+	t.Helper()
+
+	current := newPlayback(QueueEntry{ID: 1, Name: name}, s.output)
+	s.queueMutex.Lock()
+	s.currentPlayback = current
+	s.queueMutex.Unlock()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- s.playStreamer(current, newSilentStreamer(2*time.Second), testFormat)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		current.output.Lock()
+		attached := current.streamer != nil
+		current.output.Unlock()
+		if attached {
+			return result
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("playback did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPlaybackControlsWithNothingPlaying(t *testing.T) {
+	s := &Server{}
+
+	controls := map[string]func() (string, error){
+		"stop":   s.StopPlayback,
+		"skip":   s.SkipPlayback,
+		"pause":  s.PausePlayback,
+		"resume": s.ResumePlayback,
+	}
+
+	for name, control := range controls {
+		t.Run(name, func(t *testing.T) {
+			if _, err := control(); !errors.Is(err, errNothingPlaying) {
+				t.Errorf("expected errNothingPlaying, got %v", err)
+			}
+		})
+	}
+
+	if _, err := s.PlaybackStatus(); !errors.Is(err, errNothingPlaying) {
+		t.Errorf("expected errNothingPlaying from status, got %v", err)
+	}
+}
+
+func TestStopPlaybackUnblocksPlayer(t *testing.T) {
+	s := &Server{output: newIdleOutput()}
+	result := startPlayback(t, s, "clip.mp3")
+
+	name, err := s.StopPlayback()
+	if err != nil {
+		t.Fatalf("StopPlayback failed: %v", err)
+	}
+	if name != "clip.mp3" {
+		t.Errorf("expected stopped clip name, got %q", name)
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("unexpected playStreamer error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("playStreamer did not return after stop")
+	}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestStopClosesSourceBeforeLockingOutput(t *testing.T) {
+	output := newIdleOutput()
+	current := newPlayback(QueueEntry{ID: 1, Name: "clip.mp3"}, output)
+
+	// The output is held by a pull that is waiting on the source, and is only
+	// released once the source is closed.
+	output.Lock()
+	current.setSource(closerFunc(func() error {
+		output.Unlock()
+		return nil
+	}))
+
+	stopped := make(chan struct{})
+	go func() {
+		current.stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("stop waited for the output lock before closing the source")
+	}
+}
+
+func TestStoppedPlaybackDoesNotAttach(t *testing.T) {
+	current := newPlayback(QueueEntry{ID: 1, Name: "clip.mp3"}, newIdleOutput())
+	current.stop()
+
+	s := &Server{}
+	err := s.playStreamer(current, newSilentStreamer(time.Second), testFormat)
+	if !errors.Is(err, errPlaybackStopped) {
+		t.Errorf("expected errPlaybackStopped, got %v", err)
+	}
+}
+
+func TestPauseAndResumePlayback(t *testing.T) {
+	s := &Server{output: newIdleOutput()}
+	result := startPlayback(t, s, "clip.mp3")
+	defer func() {
+		_, _ = s.SkipPlayback()
+		<-result
+	}()
+
+	if _, err := s.PausePlayback(); err != nil {
+		t.Fatalf("PausePlayback failed: %v", err)
+	}
+	if _, err := s.PausePlayback(); err == nil {
+		t.Error("expected error when pausing twice")
+	}
+
+	status, err := s.PlaybackStatus()
+	if err != nil {
+		t.Fatalf("PlaybackStatus failed: %v", err)
+	}
+	if !status.Paused {
+		t.Error("expected status to report paused")
+	}
+	if status.Duration != 2*time.Second {
+		t.Errorf("expected 2s duration, got %s", status.Duration)
+	}
+
+	if _, err := s.ResumePlayback(); err != nil {
+		t.Fatalf("ResumePlayback failed: %v", err)
+	}
+	if _, err := s.ResumePlayback(); err == nil {
+		t.Error("expected error when resuming unpaused clip")
+	}
+}
+
+func TestFormatPlaybackStatus(t *testing.T) {
+	result := formatPlaybackStatus(&PlaybackStatus{
+		Name:     "clip.mp3",
+		Position: 1500 * time.Millisecond,
+		Duration: 5 * time.Second,
+	})
+	if !strings.Contains(result, "Playing: clip.mp3") || !strings.Contains(result, "2s / 5s") {
+		t.Errorf("unexpected status text: %q", result)
+	}
+
+	result = formatPlaybackStatus(&PlaybackStatus{Name: "stream", Paused: true})
+	if !strings.Contains(result, "Paused: stream") || !strings.Contains(result, "duration unknown") {
+		t.Errorf("unexpected streaming status text: %q", result)
+	}
+}
@@ -0,0 +1,166 @@
+package ximcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const (
+	PreviewsDirectory    = "previews"
+	MaxPreviewCharacters = 300
+
+	PreviewSourceStock       = "stock"
+	PreviewSourceSynthesized = "synthesized"
+)
+
+// VoicePreview is an audio sample of a voice, ready to play.
+type VoicePreview struct {
+	FilePath string
+	Voice    types.VoiceResponseModel
+	Source   string
+	Cached   bool
+}
+
+// DefaultCacheDirectory returns the XDG cache directory for downloaded and
+// synthesized previews, $XDG_CACHE_HOME/elevenlabs-mcp or
+// ~/.cache/elevenlabs-mcp.
+func DefaultCacheDirectory() string {
+	if cacheHome := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(cacheHome) {
+		return filepath.Join(cacheHome, DataDirectoryName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".cache", DataDirectoryName)
+	}
+	return filepath.Join(os.TempDir(), DataDirectoryName)
+}
+
+func (s *Server) previewDirectory() string {
+	cacheRoot := s.cacheRoot
+	if cacheRoot == "" {
+		cacheRoot = DefaultCacheDirectory()
+	}
+	return filepath.Join(cacheRoot, PreviewsDirectory)
+}
+
+// PreviewVoice returns a sample of the voice in speechOptions, or of the
+// current voice. Without text it is the voice's stock preview; with text the
+// sentence is synthesized. Either way the sample is cached outside the audio
+// history and the current voice is left unchanged.
+func (s *Server) PreviewVoice(ctx context.Context, text string, speechOptions SpeechOptions) (*VoicePreview, error) {
+	if strings.TrimSpace(text) == "" {
+		voice, err := s.speechVoice(speechOptions.Voice)
+		if err != nil {
+			return nil, err
+		}
+		return s.stockPreview(ctx, voice)
+	}
+
+	if length := utf8.RuneCountInString(text); length > MaxPreviewCharacters {
+		return nil, fmt.Errorf("preview text is %d characters, over the %d character limit; use say for longer text", length, MaxPreviewCharacters)
+	}
+
+	job, err := s.prepareSpeech(ctx, text, speechOptions)
+	if err != nil {
+		return nil, err
+	}
+	return s.synthesizedPreview(ctx, job)
+}
+
+func (s *Server) stockPreview(ctx context.Context, voice types.VoiceResponseModel) (*VoicePreview, error) {
+	if voice.PreviewURL == "" {
+		return nil, fmt.Errorf("voice %s has no preview sample; pass text to hear it synthesized", voice.Name)
+	}
+
+	preview := &VoicePreview{
+		FilePath: filepath.Join(s.previewDirectory(), voice.VoiceID+".mp3"),
+		Voice:    voice,
+		Source:   PreviewSourceStock,
+	}
+	if validateAudioFilePath(preview.FilePath) == nil {
+		preview.Cached = true
+		return preview, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, voice.PreviewURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid preview URL: %w", err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download preview: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download preview: %s", res.Status)
+	}
+
+	if err := writeCacheFile(preview.FilePath, res.Body); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+func (s *Server) synthesizedPreview(ctx context.Context, job *speechJob) (*VoicePreview, error) {
+	key, err := json.Marshal([]any{job.voice.VoiceID, job.modelID, job.format.Name, job.options, job.text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preview key: %w", err)
+	}
+	sum := sha256.Sum256(key)
+
+	preview := &VoicePreview{
+		FilePath: filepath.Join(s.previewDirectory(), fmt.Sprintf("%s-%x%s", job.voice.VoiceID, sum[:8], job.format.Extension())),
+		Voice:    job.voice,
+		Source:   PreviewSourceSynthesized,
+	}
+	if validateAudioFilePath(preview.FilePath) == nil {
+		preview.Cached = true
+		return preview, nil
+	}
+
+	var audioData bytes.Buffer
+	if err := s.synthesizeChunks(ctx, job.chunks, job.voice.VoiceID, job.modelID, job.format.Name, job.options, nil, newSampleConverter(&audioData, job.format)); err != nil {
+		return nil, err
+	}
+
+	if err := writeCacheFile(preview.FilePath, bytes.NewReader(encodeClip(job.format, audioData.Bytes()))); err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// writeCacheFile writes r to filePath via a rename, so an interrupted write
+// never leaves a truncated sample in the cache.
+func writeCacheFile(filePath string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write preview: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, r); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write preview: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write preview: %w", err)
+	}
+	if err := os.Rename(temp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to write preview: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package ximcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+func newPreviewServer(t *testing.T) (*Server, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+
+	var downloads, syntheses atomic.Int32
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/preview.mp3":
+			downloads.Add(1)
+			w.Write([]byte("stock preview"))
+		case strings.HasPrefix(r.URL.Path, "/v1/text-to-speech/"):
+			syntheses.Add(1)
+			w.Write([]byte("synthesized preview"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(standIn.Close)
+
+	s := &Server{
+		client:    client.New("test-key").WithEndpoint(standIn.URL),
+		audioRoot: t.TempDir(),
+		cacheRoot: t.TempDir(),
+		voices: []types.VoiceResponseModel{
+			{VoiceID: "abc123", Name: "Alice", PreviewURL: standIn.URL + "/preview.mp3"},
+			{VoiceID: "def456", Name: "Bob"},
+		},
+	}
+	s.currentVoice = &s.voices[0]
+	return s, &downloads, &syntheses
+}
+
+func TestPreviewVoiceStockSampleIsCached(t *testing.T) {
+	s, downloads, _ := newPreviewServer(t)
+
+	for i := range 2 {
+		preview, err := s.PreviewVoice(context.Background(), "", SpeechOptions{})
+		if err != nil {
+			t.Fatalf("PreviewVoice failed: %v", err)
+		}
+		if preview.Source != PreviewSourceStock || preview.Cached != (i > 0) {
+			t.Errorf("call %d: unexpected preview %+v", i, preview)
+		}
+		data, err := os.ReadFile(preview.FilePath)
+		if err != nil || string(data) != "stock preview" {
+			t.Errorf("unexpected cached preview %q, %v", data, err)
+		}
+	}
+
+	if got := downloads.Load(); got != 1 {
+		t.Errorf("expected 1 download, got %d", got)
+	}
+}
+
+func TestPreviewVoiceSynthesizesWithoutHistory(t *testing.T) {
+	s, _, syntheses := newPreviewServer(t)
+
+	for range 2 {
+		preview, err := s.PreviewVoice(context.Background(), "Hello there.", SpeechOptions{Voice: "bob"})
+		if err != nil {
+			t.Fatalf("PreviewVoice failed: %v", err)
+		}
+		if preview.Source != PreviewSourceSynthesized || preview.Voice.VoiceID != "def456" {
+			t.Errorf("unexpected preview %+v", preview)
+		}
+		if !strings.HasPrefix(preview.FilePath, s.previewDirectory()) {
+			t.Errorf("expected preview in the cache, got %s", preview.FilePath)
+		}
+	}
+
+	if got := syntheses.Load(); got != 1 {
+		t.Errorf("expected the synthesized preview to be cached, got %d syntheses", got)
+	}
+	if s.currentVoice.VoiceID != "abc123" {
+		t.Errorf("preview should not change the current voice, got %s", s.currentVoice.Name)
+	}
+
+	entries, err := os.ReadDir(s.audioRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no history files, got %d", len(entries))
+	}
+}
+
+func TestPreviewVoiceErrors(t *testing.T) {
+	s, _, _ := newPreviewServer(t)
+
+	if _, err := s.PreviewVoice(context.Background(), "", SpeechOptions{Voice: "bob"}); err == nil {
+		t.Error("expected error for a voice without a stock preview")
+	}
+
+	long := strings.Repeat("a", MaxPreviewCharacters+1)
+	if _, err := s.PreviewVoice(context.Background(), long, SpeechOptions{}); err == nil {
+		t.Error("expected error for overly long preview text")
+	}
+}
+
+func TestDefaultCacheDirectory(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	if got := DefaultCacheDirectory(); got != filepath.Join(cacheHome, DataDirectoryName) {
+		t.Errorf("unexpected cache directory %s", got)
+	}
+}
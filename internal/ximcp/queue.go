@@ -0,0 +1,292 @@
+package ximcp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const MaxQueueLength = 20
+
+// QueuePriority controls where new audio is inserted into the playback queue.
+type QueuePriority string
+
+const (
+	// QueueAppend plays the audio after everything already queued.
+	QueueAppend QueuePriority = "append"
+	// QueueNext plays the audio as soon as the current clip and any earlier
+	// next entries finish.
+	QueueNext QueuePriority = "next"
+	// QueueInterrupt stops the current clip and plays the audio immediately.
+	QueueInterrupt QueuePriority = "interrupt"
+)
+
+var errQueueEntryNotFound = errors.New("queue entry not found")
+
+// QueueEntry describes a clip waiting in, or taken from, the playback queue.
+type QueueEntry struct {
+	ID       uint64
+	Name     string
+	QueuedAt time.Time
+}
+
+type queueItem struct {
+	QueueEntry
+	priority QueuePriority
+	open     func() (io.ReadCloser, error)
+	decode   audioDecoder
+	release  func()
+}
+
+func parseQueuePriority(priority string) (QueuePriority, error) {
+	switch QueuePriority(strings.ToLower(strings.TrimSpace(priority))) {
+	case "", QueueAppend:
+		return QueueAppend, nil
+	case QueueNext:
+		return QueueNext, nil
+	case QueueInterrupt:
+		return QueueInterrupt, nil
+	default:
+		return "", fmt.Errorf("unknown priority %q (expected append, next, or interrupt)", priority)
+	}
+}
+
+// EnqueueAudio adds an audio file to the playback queue.
+func (s *Server) EnqueueAudio(filePath string, priority QueuePriority) (*QueueEntry, error) {
+	if err := validateAudioFilePath(filePath); err != nil {
+		return nil, err
+	}
+	decode, err := fileDecoder(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.enqueue(filePath, priority, func() (io.ReadCloser, error) {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audio file: %w", err)
+		}
+		return file, nil
+	}, decode, nil)
+}
+
+// EnqueueStream adds audio in format that is still arriving on reader to the
+// playback queue. The reader is closed if the entry is removed unplayed.
+func (s *Server) EnqueueStream(name string, reader io.ReadCloser, format AudioFormat, priority QueuePriority) (*QueueEntry, error) {
+	release := func() { reader.Close() }
+	decode, err := streamDecoder(format)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	entry, err := s.enqueue(name, priority, func() (io.ReadCloser, error) {
+		return reader, nil
+	}, decode, release)
+	if err != nil {
+		release()
+	}
+	return entry, err
+}
+
+func (s *Server) enqueue(name string, priority QueuePriority, open func() (io.ReadCloser, error), decode audioDecoder, release func()) (*QueueEntry, error) {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+
+	if len(s.queue) >= MaxQueueLength {
+		return nil, fmt.Errorf("playback queue is full (%d clips waiting); clear the queue or wait for playback to finish", MaxQueueLength)
+	}
+
+	s.nextQueueID++
+	item := &queueItem{
+		QueueEntry: QueueEntry{
+			ID:       s.nextQueueID,
+			Name:     name,
+			QueuedAt: time.Now(),
+		},
+		priority: priority,
+		open:     open,
+		decode:   decode,
+		release:  release,
+	}
+
+	switch priority {
+	case QueueNext:
+		// Entries already queued ahead of the rest keep their turn, so
+		// several next entries play in the order they were added.
+		position := 0
+		for position < len(s.queue) && (s.queue[position].priority == QueueNext || s.queue[position].priority == QueueInterrupt) {
+			position++
+		}
+		s.queue = slices.Insert(s.queue, position, item)
+	case QueueInterrupt:
+		s.queue = append([]*queueItem{item}, s.queue...)
+	default:
+		s.queue = append(s.queue, item)
+	}
+
+	if priority == QueueInterrupt && s.currentPlayback != nil {
+		s.currentPlayback.stop()
+	}
+
+	s.startPlayer()
+	s.queueCond.Signal()
+
+	entry := item.QueueEntry
+	return &entry, nil
+}
+
+// startPlayer lazily starts the goroutine that plays queued clips in order.
+// The caller must hold queueMutex.
+func (s *Server) startPlayer() {
+	if s.queueCond != nil {
+		return
+	}
+	s.queueCond = sync.NewCond(&s.queueMutex)
+	go s.runPlayer()
+}
+
+func (s *Server) runPlayer() {
+	for {
+		item, current := s.nextQueueItem()
+		err := s.playQueueItem(item, current)
+
+		s.queueMutex.Lock()
+		s.currentPlayback = nil
+		s.queueMutex.Unlock()
+
+		if err != nil && !errors.Is(err, errPlaybackStopped) {
+			log.Printf("Error playing audio %s: %v", item.Name, err)
+		}
+	}
+}
+
+// nextQueueItem blocks until a clip is queued, then removes it from the
+// queue and marks it as the current playback in the same critical section,
+// so an interrupt can never miss it.
+func (s *Server) nextQueueItem() (*queueItem, *playback) {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+
+	for len(s.queue) == 0 {
+		s.queueCond.Wait()
+	}
+
+	item := s.queue[0]
+	s.queue = s.queue[1:]
+	s.currentPlayback = newPlayback(item.QueueEntry, s.output)
+	return item, s.currentPlayback
+}
+
+func (s *Server) playQueueItem(item *queueItem, current *playback) error {
+	reader, err := item.open()
+	if err != nil {
+		return err
+	}
+	if !current.setSource(reader) {
+		reader.Close()
+		return errPlaybackStopped
+	}
+
+	streamer, format, err := item.decode(reader)
+	if err != nil {
+		reader.Close()
+		if current.isStopped() {
+			return errPlaybackStopped
+		}
+		return err
+	}
+	defer streamer.Close()
+
+	return s.playStreamer(current, streamer, format)
+}
+
+// QueueStatus returns the clip currently playing, if any, and the clips
+// waiting to play in order.
+func (s *Server) QueueStatus() (*QueueEntry, []QueueEntry) {
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+
+	var current *QueueEntry
+	if s.currentPlayback != nil {
+		entry := s.currentPlayback.entry
+		current = &entry
+	}
+
+	pending := make([]QueueEntry, 0, len(s.queue))
+	for _, item := range s.queue {
+		pending = append(pending, item.QueueEntry)
+	}
+	return current, pending
+}
+
+// ClearQueue removes every clip waiting to play and returns how many were
+// removed. The clip currently playing is not affected.
+func (s *Server) ClearQueue() int {
+	s.queueMutex.Lock()
+	removed := s.queue
+	s.queue = nil
+	s.queueMutex.Unlock()
+
+	for _, item := range removed {
+		item.discard()
+	}
+	return len(removed)
+}
+
+// RemoveFromQueue removes a single waiting clip by ID.
+func (s *Server) RemoveFromQueue(id uint64) (*QueueEntry, error) {
+	s.queueMutex.Lock()
+	var removed *queueItem
+	for index, item := range s.queue {
+		if item.ID == id {
+			removed = item
+			s.queue = append(s.queue[:index], s.queue[index+1:]...)
+			break
+		}
+	}
+	s.queueMutex.Unlock()
+
+	if removed == nil {
+		return nil, fmt.Errorf("%w: %d", errQueueEntryNotFound, id)
+	}
+
+	removed.discard()
+	entry := removed.QueueEntry
+	return &entry, nil
+}
+
+// discard releases a clip that will never be played.
+func (item *queueItem) discard() {
+	if item.release != nil {
+		item.release()
+	}
+}
+
+func formatQueue(current *QueueEntry, pending []QueueEntry) string {
+	var queueText strings.Builder
+
+	if current != nil {
+		queueText.WriteString(fmt.Sprintf("Now playing: [%d] %s\n", current.ID, current.Name))
+	} else {
+		queueText.WriteString("Nothing is playing\n")
+	}
+
+	if len(pending) == 0 {
+		queueText.WriteString("Queue is empty")
+		return queueText.String()
+	}
+
+	queueText.WriteString(fmt.Sprintf("\nQueued (%d/%d):\n", len(pending), MaxQueueLength))
+	for position, entry := range pending {
+		queueText.WriteString(fmt.Sprintf("%d. [%d] %s\n", position+1, entry.ID, entry.Name))
+	}
+
+	return queueText.String()
+}
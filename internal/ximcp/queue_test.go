@@ -0,0 +1,230 @@
+package ximcp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newIdleQueueServer returns a server whose player goroutine is never
+// started, so tests can inspect the queue without it being drained.
+func newIdleQueueServer() *Server {
+	s := &Server{output: newIdleOutput()}
+	s.queueCond = sync.NewCond(&s.queueMutex)
+	return s
+}
+
+func enqueueNamed(t *testing.T, s *Server, name string, priority QueuePriority) *QueueEntry {
+	t.Helper()
+
+	entry, err := s.enqueue(name, priority, func() (io.ReadCloser, error) {
+		return nil, errPlaybackStopped
+	}, decodeMP3, nil)
+	if err != nil {
+		t.Fatalf("enqueue %s failed: %v", name, err)
+	}
+	return entry
+}
+
+func pendingNames(s *Server) string {
+	_, pending := s.QueueStatus()
+	names := make([]string, 0, len(pending))
+	for _, entry := range pending {
+		names = append(names, entry.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestParseQueuePriority(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected QueuePriority
+		valid    bool
+	}{
+		{"", QueueAppend, true},
+		{"append", QueueAppend, true},
+		{" Next ", QueueNext, true},
+		{"INTERRUPT", QueueInterrupt, true},
+		{"later", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			priority, err := parseQueuePriority(tt.input)
+			if tt.valid && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatal("expected error for unknown priority")
+			}
+			if priority != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, priority)
+			}
+		})
+	}
+}
+
+func TestEnqueueOrdering(t *testing.T) {
+	s := newIdleQueueServer()
+
+	enqueueNamed(t, s, "first", QueueAppend)
+	enqueueNamed(t, s, "second", QueueAppend)
+	enqueueNamed(t, s, "urgent", QueueNext)
+	enqueueNamed(t, s, "third", QueueAppend)
+	enqueueNamed(t, s, "also urgent", QueueNext)
+
+	if names := pendingNames(s); names != "urgent,also urgent,first,second,third" {
+		t.Errorf("unexpected queue order: %s", names)
+	}
+}
+
+func TestEnqueueInterruptStopsCurrent(t *testing.T) {
+	s := newIdleQueueServer()
+	current := newPlayback(QueueEntry{ID: 99, Name: "long.mp3"}, s.output)
+	s.currentPlayback = current
+
+	enqueueNamed(t, s, "queued", QueueAppend)
+	if current.isStopped() {
+		t.Fatal("append must not stop the current clip")
+	}
+
+	enqueueNamed(t, s, "alert", QueueInterrupt)
+	if !current.isStopped() {
+		t.Error("interrupt should stop the current clip")
+	}
+	if names := pendingNames(s); names != "alert,queued" {
+		t.Errorf("interrupting clip should be first, got %s", names)
+	}
+}
+
+func TestEnqueueFullQueue(t *testing.T) {
+	s := newIdleQueueServer()
+
+	for i := range MaxQueueLength {
+		enqueueNamed(t, s, fmt.Sprintf("clip-%d", i), QueueAppend)
+	}
+
+	_, err := s.enqueue("overflow", QueueAppend, nil, nil, nil)
+	if err == nil {
+		t.Fatal("expected error when queue is full")
+	}
+	if !strings.Contains(err.Error(), "playback queue is full") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestEnqueueStreamClosesReaderWhenFull(t *testing.T) {
+	s := newIdleQueueServer()
+	for i := range MaxQueueLength {
+		enqueueNamed(t, s, fmt.Sprintf("clip-%d", i), QueueAppend)
+	}
+
+	buffer := newAudioBuffer()
+	reader := buffer.NewReader()
+	if _, err := s.EnqueueStream("stream", reader, AudioFormat{}, QueueAppend); err == nil {
+		t.Fatal("expected error when queue is full")
+	}
+
+	if _, err := reader.Read(make([]byte, 1)); !errors.Is(err, errReaderClosed) {
+		t.Errorf("expected rejected stream reader to be closed, got %v", err)
+	}
+}
+
+func TestRemoveFromQueue(t *testing.T) {
+	s := newIdleQueueServer()
+
+	enqueueNamed(t, s, "first", QueueAppend)
+	second := enqueueNamed(t, s, "second", QueueAppend)
+	enqueueNamed(t, s, "third", QueueAppend)
+
+	entry, err := s.RemoveFromQueue(second.ID)
+	if err != nil {
+		t.Fatalf("RemoveFromQueue failed: %v", err)
+	}
+	if entry.Name != "second" {
+		t.Errorf("removed wrong entry: %q", entry.Name)
+	}
+	if names := pendingNames(s); names != "first,third" {
+		t.Errorf("unexpected queue after removal: %s", names)
+	}
+
+	if _, err := s.RemoveFromQueue(second.ID); !errors.Is(err, errQueueEntryNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestClearQueueReleasesStreams(t *testing.T) {
+	s := newIdleQueueServer()
+
+	buffer := newAudioBuffer()
+	reader := buffer.NewReader()
+	if _, err := s.EnqueueStream("stream", reader, AudioFormat{}, QueueAppend); err != nil {
+		t.Fatal(err)
+	}
+	enqueueNamed(t, s, "file", QueueAppend)
+
+	if removed := s.ClearQueue(); removed != 2 {
+		t.Errorf("expected 2 entries removed, got %d", removed)
+	}
+	if names := pendingNames(s); names != "" {
+		t.Errorf("expected empty queue, got %s", names)
+	}
+	if _, err := reader.Read(make([]byte, 1)); !errors.Is(err, errReaderClosed) {
+		t.Errorf("expected cleared stream reader to be closed, got %v", err)
+	}
+}
+
+func TestPlayerConsumesQueueInOrder(t *testing.T) {
+	s := &Server{output: newIdleOutput()}
+
+	var mutex sync.Mutex
+	var played []string
+	done := make(chan struct{})
+
+	for _, name := range []string{"one", "two", "three"} {
+		_, err := s.enqueue(name, QueueAppend, func() (io.ReadCloser, error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			played = append(played, name)
+			if len(played) == 3 {
+				close(done)
+			}
+			return nil, errPlaybackStopped
+		}, decodeMP3, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("player did not drain the queue")
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if strings.Join(played, ",") != "one,two,three" {
+		t.Errorf("clips played out of order: %v", played)
+	}
+}
+
+func TestFormatQueue(t *testing.T) {
+	result := formatQueue(nil, nil)
+	if !strings.Contains(result, "Nothing is playing") || !strings.Contains(result, "Queue is empty") {
+		t.Errorf("unexpected empty queue text: %q", result)
+	}
+
+	result = formatQueue(&QueueEntry{ID: 1, Name: "now.mp3"}, []QueueEntry{{ID: 2, Name: "next.mp3"}})
+	if !strings.Contains(result, "Now playing: [1] now.mp3") {
+		t.Errorf("expected current clip in %q", result)
+	}
+	if !strings.Contains(result, "1. [2] next.mp3") {
+		t.Errorf("expected queued clip in %q", result)
+	}
+}
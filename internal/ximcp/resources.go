@@ -0,0 +1,172 @@
+package ximcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	AudioResourcePrefix   = "xi://audio/"
+	AudioResourceTemplate = AudioResourcePrefix + "{name}"
+
+	DefaultMaxInlineAudioBytes = 1 << 20
+
+	transcriptMIMEType  = "text/plain"
+	methodListResources = "resources/list"
+)
+
+// audioResourceTemplate covers every clip and transcript; the concrete
+// resources are listed per session by listAudioResources.
+var audioResourceTemplate = &mcp.ResourceTemplate{
+	Name:        "audio",
+	Title:       "Generated audio",
+	Description: "Generated clips (<name>.mp3, <name>.wav, or <name>.opus) and their transcripts (<name>.txt)",
+	URITemplate: AudioResourceTemplate,
+}
+
+func (s *Server) setupResources() {
+	s.mcpServer.AddResourceTemplate(audioResourceTemplate, s.readAudioResource)
+	s.mcpServer.AddReceivingMiddleware(s.listAudioResources)
+}
+
+// listAudioResources answers resources/list with the clips in the calling
+// client's project directory, read from disk on every request, so each
+// session only sees its own project and never a stale snapshot.
+func (s *Server) listAudioResources(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if method != methodListResources {
+			return next(ctx, method, req)
+		}
+
+		session, _ := req.GetSession().(*mcp.ServerSession)
+		ctx = withAudioDirectory(ctx, s.sessionAudioDirectory(ctx, session))
+		audioFiles, err := s.GetAudioHistory(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list audio resources: %w", err)
+		}
+
+		result := &mcp.ListResourcesResult{Resources: []*mcp.Resource{}}
+		for _, audioFile := range audioFiles {
+			result.Resources = append(result.Resources, audioFileResources(audioFile)...)
+		}
+		return result, nil
+	}
+}
+
+// notifyResourcesChanged tells clients to list resources again. The SDK has
+// no direct way to send the notification, but it sends one whenever a
+// template is added, and re-adding ours replaces it unchanged.
+func (s *Server) notifyResourcesChanged() {
+	if s.mcpServer == nil {
+		return
+	}
+	s.mcpServer.AddResourceTemplate(audioResourceTemplate, s.readAudioResource)
+}
+
+func audioFileResources(audioFile AudioFile) []*mcp.Resource {
+	resources := []*mcp.Resource{{
+		URI:         AudioResourcePrefix + audioFile.Name,
+		Name:        audioFile.Name,
+		Description: audioFile.Summary,
+		MIMEType:    audioMIMEType(audioFile.Name),
+	}}
+	if info, err := os.Stat(audioFile.FilePath); err == nil {
+		resources[0].Size = info.Size()
+	}
+
+	transcriptName := trimAudioExtension(audioFile.Name) + ".txt"
+	if info, err := os.Stat(filepath.Join(filepath.Dir(audioFile.FilePath), transcriptName)); err == nil {
+		resources = append(resources, &mcp.Resource{
+			URI:         AudioResourcePrefix + transcriptName,
+			Name:        transcriptName,
+			Description: fmt.Sprintf("Transcript of %s", audioFile.Name),
+			MIMEType:    transcriptMIMEType,
+			Size:        info.Size(),
+		})
+	}
+	return resources
+}
+
+// readAudioResource serves a clip as an audio blob or its transcript as text.
+func (s *Server) readAudioResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	name, ok := strings.CutPrefix(uri, AudioResourcePrefix)
+	if !ok || name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	var mimeType string
+	switch {
+	case isAudioFile(name):
+		mimeType = audioMIMEType(name)
+	case filepath.Ext(name) == ".txt":
+		mimeType = transcriptMIMEType
+	default:
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.sessionAudioDirectory(ctx, req.Session), name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	contents := &mcp.ResourceContents{URI: uri, MIMEType: mimeType}
+	if mimeType == transcriptMIMEType {
+		contents.Text = string(data)
+	} else {
+		contents.Blob = data
+	}
+
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+}
+
+func (s *Server) maxInlineAudioBytes() int64 {
+	if s.inlineAudioLimit > 0 {
+		return s.inlineAudioLimit
+	}
+	return DefaultMaxInlineAudioBytes
+}
+
+// inlineAudioContent returns the clip at filePath as embedded audio for
+// clients that cannot read our filesystem, or as a link to its resource when
+// it is larger than the inline limit. It returns nil when inline audio is
+// disabled for the call.
+func (s *Server) inlineAudioContent(filePath string, inline *bool) (mcp.Content, error) {
+	enabled := s.inlineAudio
+	if inline != nil {
+		enabled = *inline
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access audio file: %w", err)
+	}
+
+	name := filepath.Base(filePath)
+	if size := info.Size(); size > s.maxInlineAudioBytes() {
+		return &mcp.ResourceLink{
+			URI:         AudioResourcePrefix + name,
+			Name:        name,
+			Description: fmt.Sprintf("Audio is %d bytes, over the %d byte inline limit", size, s.maxInlineAudioBytes()),
+			MIMEType:    audioMIMEType(name),
+			Size:        &size,
+		}, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audio file: %w", err)
+	}
+	return &mcp.AudioContent{Data: data, MIMEType: audioMIMEType(name)}, nil
+}
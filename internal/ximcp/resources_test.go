@@ -0,0 +1,196 @@
+package ximcp
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAudioResourcesListAndRead(t *testing.T) {
+	s := &Server{audioRoot: t.TempDir()}
+	filePath, err := s.saveAudioFiles(context.Background(), "Hello resources", []byte("fake mp3 data"), AudioMetadata{})
+	if err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+	name := filepath.Base(filePath)
+	transcript := strings.TrimSuffix(name, ".mp3") + ".txt"
+
+	session := connectTestClient(t, s, nil)
+	ctx := context.Background()
+
+	listed, err := session.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	mimeTypes := map[string]string{}
+	for _, resource := range listed.Resources {
+		mimeTypes[resource.URI] = resource.MIMEType
+	}
+	if mimeTypes[AudioResourcePrefix+name] != "audio/mpeg" {
+		t.Errorf("expected audio resource for %s, got %v", name, mimeTypes)
+	}
+	if mimeTypes[AudioResourcePrefix+transcript] != "text/plain" {
+		t.Errorf("expected transcript resource for %s, got %v", transcript, mimeTypes)
+	}
+
+	audio, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: AudioResourcePrefix + name})
+	if err != nil {
+		t.Fatalf("ReadResource audio failed: %v", err)
+	}
+	if string(audio.Contents[0].Blob) != "fake mp3 data" {
+		t.Errorf("unexpected audio blob %q", audio.Contents[0].Blob)
+	}
+
+	text, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: AudioResourcePrefix + transcript})
+	if err != nil {
+		t.Fatalf("ReadResource transcript failed: %v", err)
+	}
+	if text.Contents[0].Text != "Hello resources" {
+		t.Errorf("unexpected transcript %q", text.Contents[0].Text)
+	}
+}
+
+func TestAudioResourcesAreScopedToSession(t *testing.T) {
+	s := &Server{audioRoot: t.TempDir()}
+	s.mcpServer = mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	s.setupResources()
+
+	ctx := context.Background()
+	connect := func(root string) *mcp.ClientSession {
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := s.mcpServer.Connect(ctx, serverTransport, nil)
+		if err != nil {
+			t.Fatalf("server connect failed: %v", err)
+		}
+		t.Cleanup(func() { serverSession.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		client.AddRoots(&mcp.Root{URI: "file://" + root})
+		session, err := client.Connect(ctx, clientTransport, nil)
+		if err != nil {
+			t.Fatalf("client connect failed: %v", err)
+		}
+		t.Cleanup(func() { session.Close() })
+		return session
+	}
+	alpha := connect("/home/user/code/alpha")
+	beta := connect("/home/user/code/beta")
+
+	project := filepath.Join(s.audioRoot, ProjectsDirectory, projectDirectoryName("/home/user/code/alpha"))
+	filePath, err := s.saveAudioFiles(withAudioDirectory(ctx, project), "Alpha clip", []byte("fake mp3 data"), AudioMetadata{})
+	if err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+	uri := AudioResourcePrefix + filepath.Base(filePath)
+
+	listed, err := alpha.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	if len(listed.Resources) != 2 || listed.Resources[0].URI != uri {
+		t.Errorf("expected the new clip and its transcript, got %+v", listed.Resources)
+	}
+	if _, err := alpha.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri}); err != nil {
+		t.Errorf("ReadResource failed: %v", err)
+	}
+
+	listed, err = beta.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListResources failed: %v", err)
+	}
+	if len(listed.Resources) != 0 {
+		t.Errorf("expected no resources for another project, got %+v", listed.Resources)
+	}
+	if _, err := beta.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri}); err == nil {
+		t.Error("expected another project's clip to be unreadable")
+	}
+}
+
+func TestAudioResourceRejectsUnknownNames(t *testing.T) {
+	session := connectTestClient(t, &Server{audioRoot: t.TempDir()}, nil)
+
+	for _, uri := range []string{
+		AudioResourcePrefix + "missing.mp3",
+		AudioResourcePrefix + "notes.md",
+		AudioResourcePrefix + "..%2Fsecret.txt",
+	} {
+		if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: uri}); err == nil {
+			t.Errorf("expected error reading %s", uri)
+		}
+	}
+}
+
+func TestSavingAudioNotifiesResourceListChanged(t *testing.T) {
+	changed := make(chan struct{}, 1)
+	s := &Server{audioRoot: t.TempDir()}
+	connectTestClient(t, s, &mcp.ClientOptions{
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		},
+	})
+
+	if _, err := s.saveAudioFiles(context.Background(), "New clip", []byte("fake mp3 data"), AudioMetadata{}); err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a resource list changed notification")
+	}
+}
+
+func TestInlineAudioContent(t *testing.T) {
+	s := &Server{audioRoot: t.TempDir(), inlineAudioLimit: 16}
+	small, err := s.saveAudioFiles(context.Background(), "small", []byte("tiny mp3"), AudioMetadata{})
+	if err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+	large, err := s.saveAudioFiles(context.Background(), "large", []byte(strings.Repeat("x", 32)), AudioMetadata{})
+	if err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+
+	content, err := s.inlineAudioContent(small, nil)
+	if err != nil || content != nil {
+		t.Fatalf("expected no content when inline audio is disabled, got %v, %v", content, err)
+	}
+
+	enabled := true
+	content, err = s.inlineAudioContent(small, &enabled)
+	if err != nil {
+		t.Fatalf("inlineAudioContent failed: %v", err)
+	}
+	audio, ok := content.(*mcp.AudioContent)
+	if !ok {
+		t.Fatalf("expected AudioContent, got %T", content)
+	}
+	if string(audio.Data) != "tiny mp3" || audio.MIMEType != "audio/mpeg" {
+		t.Errorf("unexpected audio content: %q %s", audio.Data, audio.MIMEType)
+	}
+
+	s.inlineAudio = true
+	content, err = s.inlineAudioContent(large, nil)
+	if err != nil {
+		t.Fatalf("inlineAudioContent failed: %v", err)
+	}
+	link, ok := content.(*mcp.ResourceLink)
+	if !ok {
+		t.Fatalf("expected ResourceLink for oversized audio, got %T", content)
+	}
+	if !strings.HasPrefix(link.URI, AudioResourcePrefix) || *link.Size != 32 {
+		t.Errorf("unexpected resource link: %s (%d bytes)", link.URI, *link.Size)
+	}
+
+	disabled := false
+	if content, _ := s.inlineAudioContent(small, &disabled); content != nil {
+		t.Error("per-call flag should override the server default")
+	}
+}
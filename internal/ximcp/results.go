@@ -0,0 +1,225 @@
+package ximcp
+
+import (
+	"time"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+// Structured tool results. Each is registered as the tool's output schema and
+// returned alongside the human-readable text content.
+
+type VoiceResult struct {
+	VoiceID     string            `json:"voice_id" jsonschema:"ID of the voice"`
+	Name        string            `json:"name" jsonschema:"Display name of the voice"`
+	Category    string            `json:"category,omitempty" jsonschema:"Voice category, such as premade or cloned"`
+	Description string            `json:"description,omitempty" jsonschema:"Description of the voice"`
+	Labels      map[string]string `json:"labels,omitempty" jsonschema:"Voice labels such as accent, gender, age, and use case"`
+	PreviewURL  string            `json:"preview_url,omitempty" jsonschema:"URL of a short sample of the voice"`
+	Selected    bool              `json:"selected" jsonschema:"Whether this is the currently selected voice"`
+}
+
+type GetVoicesResult struct {
+	Voices         []VoiceResult `json:"voices" jsonschema:"Voices on this page that match the filters"`
+	CurrentVoiceID string        `json:"current_voice_id,omitempty" jsonschema:"ID of the currently selected voice"`
+	Total          int           `json:"total" jsonschema:"Number of voices matching the filters across all pages"`
+	Offset         int           `json:"offset" jsonschema:"Index of the first voice on this page"`
+	NextOffset     int           `json:"next_offset,omitempty" jsonschema:"Offset of the next page, when there are more matching voices"`
+	Warning        string        `json:"warning,omitempty" jsonschema:"Set when the voice list could not be refreshed and cached voices are shown"`
+}
+
+type SetVoiceResult struct {
+	VoiceID  string                 `json:"voice_id" jsonschema:"ID of the selected voice"`
+	Name     string                 `json:"name" jsonschema:"Display name of the selected voice"`
+	Settings types.SynthesisOptions `json:"settings" jsonschema:"Default voice settings used for generation"`
+}
+
+type AddVoiceResult struct {
+	VoiceID  string `json:"voice_id" jsonschema:"ID of the new voice"`
+	Name     string `json:"name" jsonschema:"Display name of the new voice"`
+	Selected bool   `json:"selected" jsonschema:"Whether the new voice is now the currently selected voice"`
+}
+
+type DeleteVoiceResult struct {
+	VoiceID string `json:"voice_id" jsonschema:"ID of the deleted voice"`
+	Name    string `json:"name" jsonschema:"Display name of the deleted voice"`
+}
+
+type VoiceSettingsResult struct {
+	VoiceID  string                 `json:"voice_id" jsonschema:"ID of the voice"`
+	Name     string                 `json:"name" jsonschema:"Display name of the voice"`
+	Settings types.SynthesisOptions `json:"settings" jsonschema:"Settings saved on the voice"`
+}
+
+type SpeechResult struct {
+	FilePath        string                 `json:"file_path" jsonschema:"Path of the saved audio file"`
+	VoiceID         string                 `json:"voice_id" jsonschema:"ID of the voice used"`
+	VoiceName       string                 `json:"voice_name,omitempty" jsonschema:"Display name of the voice used"`
+	ModelID         string                 `json:"model_id" jsonschema:"ID of the model used"`
+	OutputFormat    string                 `json:"output_format" jsonschema:"Audio format requested from ElevenLabs"`
+	Settings        types.SynthesisOptions `json:"settings" jsonschema:"Voice settings used for synthesis"`
+	Chunks          int                    `json:"chunks" jsonschema:"Number of synthesis requests the text was split into"`
+	DurationSeconds float64                `json:"duration_seconds,omitempty" jsonschema:"Playing time of the saved audio"`
+	QueueID         uint64                 `json:"queue_id,omitempty" jsonschema:"Playback queue entry ID, when the audio was queued"`
+}
+
+type PreviewVoiceResult struct {
+	VoiceID         string  `json:"voice_id" jsonschema:"ID of the previewed voice"`
+	VoiceName       string  `json:"voice_name" jsonschema:"Display name of the previewed voice"`
+	Source          string  `json:"source" jsonschema:"stock for the voice's own preview sample, synthesized for the given text"`
+	FilePath        string  `json:"file_path" jsonschema:"Path of the cached preview audio"`
+	Cached          bool    `json:"cached" jsonschema:"Whether the preview was served from the cache"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty" jsonschema:"Playing time of the preview"`
+	QueueID         uint64  `json:"queue_id,omitempty" jsonschema:"Playback queue entry ID, when the preview was played"`
+}
+
+type SoundEffectResult struct {
+	FilePath        string  `json:"file_path" jsonschema:"Path of the saved audio file"`
+	PromptInfluence float64 `json:"prompt_influence" jsonschema:"Prompt influence used for generation"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty" jsonschema:"Playing time of the saved audio"`
+	QueueID         uint64  `json:"queue_id,omitempty" jsonschema:"Playback queue entry ID, when the sound was played"`
+}
+
+type ConvertVoiceResult struct {
+	FilePath        string                 `json:"file_path" jsonschema:"Path of the saved audio file"`
+	SourceFile      string                 `json:"source_file" jsonschema:"Path of the converted recording"`
+	VoiceID         string                 `json:"voice_id" jsonschema:"ID of the target voice"`
+	VoiceName       string                 `json:"voice_name,omitempty" jsonschema:"Display name of the target voice"`
+	ModelID         string                 `json:"model_id" jsonschema:"ID of the speech-to-speech model used"`
+	OutputFormat    string                 `json:"output_format" jsonschema:"Audio format requested from ElevenLabs"`
+	Settings        types.SynthesisOptions `json:"settings" jsonschema:"Voice settings used for conversion"`
+	DurationSeconds float64                `json:"duration_seconds,omitempty" jsonschema:"Playing time of the saved audio"`
+	QueueID         uint64                 `json:"queue_id,omitempty" jsonschema:"Playback queue entry ID, when the audio was played"`
+}
+
+type TranscriptWordResult struct {
+	Text      string  `json:"text" jsonschema:"The word"`
+	Start     float64 `json:"start" jsonschema:"When the word starts, in seconds"`
+	End       float64 `json:"end" jsonschema:"When the word ends, in seconds"`
+	SpeakerID string  `json:"speaker_id,omitempty" jsonschema:"Speaker of the word, when diarization was requested"`
+}
+
+type TranscriptResult struct {
+	FilePath            string                 `json:"file_path" jsonschema:"Path of the transcribed audio file"`
+	Text                string                 `json:"text" jsonschema:"Transcript, split into speaker turns when diarization was requested"`
+	LanguageCode        string                 `json:"language_code,omitempty" jsonschema:"Detected or requested language"`
+	LanguageProbability float64                `json:"language_probability,omitempty" jsonschema:"Confidence in the detected language from 0 to 1"`
+	Words               []TranscriptWordResult `json:"words,omitempty" jsonschema:"Words with timestamps, when timestamps or diarization were requested"`
+	TextFilePath        string                 `json:"text_file_path" jsonschema:"Path of the saved plain-text transcript"`
+	JSONFilePath        string                 `json:"json_file_path" jsonschema:"Path of the saved JSON transcript"`
+}
+
+type PlayResult struct {
+	QueueID         uint64  `json:"queue_id" jsonschema:"Playback queue entry ID"`
+	FilePath        string  `json:"file_path" jsonschema:"Path of the queued audio file"`
+	Priority        string  `json:"priority" jsonschema:"Where the audio was queued"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty" jsonschema:"Playing time of the audio file"`
+}
+
+type HistoryEntry struct {
+	Name            string  `json:"name" jsonschema:"File name of the audio"`
+	FilePath        string  `json:"file_path" jsonschema:"Path of the audio file"`
+	Summary         string  `json:"summary" jsonschema:"Truncated text the audio was generated from"`
+	Kind            string  `json:"kind,omitempty" jsonschema:"sound_effect for generated sound effects, voice_conversion for converted recordings; empty for speech"`
+	VoiceID         string  `json:"voice_id,omitempty" jsonschema:"ID of the voice used"`
+	VoiceName       string  `json:"voice_name,omitempty" jsonschema:"Display name of the voice used"`
+	ModelID         string  `json:"model_id,omitempty" jsonschema:"ID of the model used"`
+	OutputFormat    string  `json:"output_format,omitempty" jsonschema:"Audio format requested from ElevenLabs"`
+	SourceFile      string  `json:"source_file,omitempty" jsonschema:"Recording a voice conversion was made from"`
+	SourceVoiceName string  `json:"source_voice_name,omitempty" jsonschema:"Voice of the converted recording, when it was a generated clip"`
+	CreatedAt       string  `json:"created_at,omitempty" jsonschema:"When the audio was generated, in RFC 3339 format"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty" jsonschema:"Playing time of the audio"`
+}
+
+type HistoryResult struct {
+	Files []HistoryEntry `json:"files" jsonschema:"Generated audio files, newest first"`
+}
+
+func newGetVoicesResult(voices []types.VoiceResponseModel, currentVoice *types.VoiceResponseModel) *GetVoicesResult {
+	result := &GetVoicesResult{Voices: make([]VoiceResult, 0, len(voices))}
+	if currentVoice != nil {
+		result.CurrentVoiceID = currentVoice.VoiceID
+	}
+
+	for _, voice := range voices {
+		result.Voices = append(result.Voices, VoiceResult{
+			VoiceID:     voice.VoiceID,
+			Name:        voice.Name,
+			Category:    voice.Category,
+			Description: voice.Description,
+			Labels:      voice.Labels,
+			PreviewURL:  voice.PreviewURL,
+			Selected:    voice.VoiceID == result.CurrentVoiceID,
+		})
+	}
+	result.Total = len(voices)
+	return result
+}
+
+func newSpeechResult(audio *GeneratedAudio) *SpeechResult {
+	return &SpeechResult{
+		FilePath:        audio.FilePath,
+		VoiceID:         audio.VoiceID,
+		VoiceName:       audio.VoiceName,
+		ModelID:         audio.ModelID,
+		OutputFormat:    audio.OutputFormat,
+		Settings:        audio.Settings,
+		Chunks:          audio.Chunks,
+		DurationSeconds: audio.Duration.Seconds(),
+		QueueID:         audio.QueueID,
+	}
+}
+
+func newConvertVoiceResult(audio *GeneratedAudio) *ConvertVoiceResult {
+	return &ConvertVoiceResult{
+		FilePath:        audio.FilePath,
+		SourceFile:      audio.SourceFile,
+		VoiceID:         audio.VoiceID,
+		VoiceName:       audio.VoiceName,
+		ModelID:         audio.ModelID,
+		OutputFormat:    audio.OutputFormat,
+		Settings:        audio.Settings,
+		DurationSeconds: audio.Duration.Seconds(),
+		QueueID:         audio.QueueID,
+	}
+}
+
+func newHistoryResult(audioFiles []AudioFile) *HistoryResult {
+	result := &HistoryResult{Files: make([]HistoryEntry, 0, len(audioFiles))}
+
+	for _, audioFile := range audioFiles {
+		entry := HistoryEntry{
+			Name:            audioFile.Name,
+			FilePath:        audioFile.FilePath,
+			Summary:         audioFile.Summary,
+			Kind:            audioFile.Kind,
+			VoiceID:         audioFile.VoiceID,
+			VoiceName:       audioFile.VoiceName,
+			ModelID:         audioFile.ModelID,
+			OutputFormat:    audioFile.OutputFormat,
+			SourceFile:      audioFile.SourceFile,
+			SourceVoiceName: audioFile.SourceVoiceName,
+			DurationSeconds: audioFile.Duration.Seconds(),
+		}
+		if !audioFile.CreatedAt.IsZero() {
+			entry.CreatedAt = audioFile.CreatedAt.Format(time.RFC3339)
+		}
+		result.Files = append(result.Files, entry)
+	}
+	return result
+}
+
+func newTranscriptResult(transcript *Transcript) *TranscriptResult {
+	result := &TranscriptResult{
+		FilePath:            transcript.FilePath,
+		Text:                transcript.Text,
+		LanguageCode:        transcript.LanguageCode,
+		LanguageProbability: transcript.LanguageProbability,
+		TextFilePath:        transcript.TextFilePath,
+		JSONFilePath:        transcript.JSONFilePath,
+	}
+	for _, word := range transcript.Words {
+		result.Words = append(result.Words, TranscriptWordResult(word))
+	}
+	return result
+}
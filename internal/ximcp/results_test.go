@@ -0,0 +1,173 @@
+package ximcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+// connectTestClient registers the tools and resources of s on a fresh MCP
+// server and returns a client session connected to it in memory.
+func connectTestClient(t *testing.T, s *Server, options *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	s.mcpServer = mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	s.setupTools()
+	s.setupResources()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := s.mcpServer.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, options)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	t.Cleanup(func() { clientSession.Close() })
+
+	return clientSession
+}
+
+func decodeStructured(t *testing.T, result *mcp.CallToolResult, out any) {
+	t.Helper()
+
+	content, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("failed to marshal structured content: %v", err)
+	}
+	if err := json.Unmarshal(content, out); err != nil {
+		t.Fatalf("failed to decode structured content %s: %v", content, err)
+	}
+}
+
+func TestToolsDeclareOutputSchemas(t *testing.T) {
+	session := connectTestClient(t, &Server{}, nil)
+
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+
+	withSchema := map[string]bool{}
+	for _, tool := range tools.Tools {
+		withSchema[tool.Name] = tool.OutputSchema != nil
+	}
+
+	for _, name := range []string{"get_voices", "history", "say", "read", "set_voice", "play"} {
+		if !withSchema[name] {
+			t.Errorf("expected %s to declare an output schema", name)
+		}
+	}
+}
+
+func TestSetVoiceStructuredOutput(t *testing.T) {
+	s := &Server{
+		voices: []types.VoiceResponseModel{
+			{VoiceID: "abc123", Name: "Alice"},
+			{VoiceID: "def456", Name: "Bob"},
+		},
+	}
+	session := connectTestClient(t, s, nil)
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "set_voice",
+		Arguments: map[string]any{"voice_id": "def456"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %+v", result.Content)
+	}
+
+	var voice SetVoiceResult
+	decodeStructured(t, result, &voice)
+	if voice.VoiceID != "def456" || voice.Name != "Bob" {
+		t.Errorf("unexpected structured output: %+v", voice)
+	}
+	if len(result.Content) == 0 {
+		t.Error("expected text content alongside structured output")
+	}
+
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "set_voice",
+		Arguments: map[string]any{"voice_id": "missing"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected tool error for unknown voice")
+	}
+}
+
+func TestHistoryStructuredOutput(t *testing.T) {
+	s := &Server{audioRoot: t.TempDir()}
+	metadata := AudioMetadata{
+		VoiceID:   "abc123",
+		VoiceName: "Alice",
+		ModelID:   "eleven_flash_v2_5",
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if _, err := s.saveAudioFiles(context.Background(), "Hello from history", []byte("not really mp3"), metadata); err != nil {
+		t.Fatalf("saveAudioFiles failed: %v", err)
+	}
+
+	session := connectTestClient(t, s, nil)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "history"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	var history HistoryResult
+	decodeStructured(t, result, &history)
+	if len(history.Files) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(history.Files))
+	}
+
+	entry := history.Files[0]
+	if entry.Summary != "Hello from history" {
+		t.Errorf("unexpected summary %q", entry.Summary)
+	}
+	if entry.VoiceID != "abc123" || entry.ModelID != "eleven_flash_v2_5" {
+		t.Errorf("unexpected metadata in entry: %+v", entry)
+	}
+	if entry.CreatedAt != "2025-01-02T03:04:05Z" {
+		t.Errorf("unexpected created_at %q", entry.CreatedAt)
+	}
+	if entry.FilePath == "" {
+		t.Error("expected file path in entry")
+	}
+}
+
+func TestNewGetVoicesResultMarksSelected(t *testing.T) {
+	voices := []types.VoiceResponseModel{
+		{VoiceID: "abc123", Name: "Alice", Category: "premade"},
+		{VoiceID: "def456", Name: "Bob", Category: "cloned", Labels: map[string]string{"accent": "british"}, PreviewURL: "https://example.com/bob.mp3"},
+	}
+
+	result := newGetVoicesResult(voices, &voices[1])
+	if result.CurrentVoiceID != "def456" {
+		t.Errorf("expected current voice def456, got %q", result.CurrentVoiceID)
+	}
+	if result.Voices[0].Selected || !result.Voices[1].Selected {
+		t.Errorf("unexpected selection: %+v", result.Voices)
+	}
+	if result.Voices[1].Labels["accent"] != "british" || result.Voices[1].PreviewURL == "" {
+		t.Errorf("expected labels and preview URL, got %+v", result.Voices[1])
+	}
+
+	empty := newGetVoicesResult(nil, nil)
+	if empty.Voices == nil {
+		t.Error("expected an empty voice list rather than nil")
+	}
+}
@@ -0,0 +1,79 @@
+package ximcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RetentionPolicy limits the generated clips kept in each audio directory.
+// Zero values mean no limit.
+type RetentionPolicy struct {
+	// MaxFiles is the number of most recent clips to keep.
+	MaxFiles int
+	// MaxAge is how long a clip is kept after it was generated.
+	MaxAge time.Duration
+}
+
+func (r RetentionPolicy) enabled() bool {
+	return r.MaxFiles > 0 || r.MaxAge > 0
+}
+
+// pruneAudioFiles removes clips in directory, with their sidecar files, that
+// fall outside the retention policy. It returns the number of clips removed.
+func (s *Server) pruneAudioFiles(directory string) (int, error) {
+	if !s.retention.enabled() {
+		return 0, nil
+	}
+
+	files, err := os.ReadDir(directory)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s directory: %w", directory, err)
+	}
+
+	var clips []os.DirEntry
+	for _, file := range files {
+		if !file.IsDir() && isAudioFile(file.Name()) {
+			clips = append(clips, file)
+		}
+	}
+	// Clip names start with a millisecond timestamp, so this is newest first.
+	sort.Slice(clips, func(firstIndex, secondIndex int) bool {
+		return clips[firstIndex].Name() > clips[secondIndex].Name()
+	})
+
+	cutoff := time.Now().Add(-s.retention.MaxAge)
+	removed := 0
+	for index, clip := range clips {
+		expired := false
+		if s.retention.MaxAge > 0 {
+			if info, err := clip.Info(); err == nil && info.ModTime().Before(cutoff) {
+				expired = true
+			}
+		}
+		if !expired && (s.retention.MaxFiles == 0 || index < s.retention.MaxFiles) {
+			continue
+		}
+
+		if err := removeAudioFiles(filepath.Join(directory, clip.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// removeAudioFiles deletes a clip and its text, metadata, and transcript
+// sidecars.
+func removeAudioFiles(filePath string) error {
+	base := trimAudioExtension(filePath)
+	transcript := base + TranscriptFileSuffix
+	for _, path := range []string{filePath, base + ".txt", base + MetadataFileSuffix, transcript + ".txt", transcript + ".json"} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package ximcp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeClip(t *testing.T, directory string, timestamp int64, modTime time.Time) string {
+	t.Helper()
+
+	base := filepath.Join(directory, fmt.Sprintf("%d-aaaaa", timestamp))
+	for _, suffix := range []string{".mp3", ".txt", MetadataFileSuffix, TranscriptFileSuffix + ".json"} {
+		if err := os.WriteFile(base+suffix, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chtimes(base+".mp3", modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	return base
+}
+
+func TestPruneAudioFilesMaxFiles(t *testing.T) {
+	directory := t.TempDir()
+	now := time.Now()
+	oldest := writeClip(t, directory, 1000, now)
+	middle := writeClip(t, directory, 2000, now)
+	newest := writeClip(t, directory, 3000, now)
+
+	s := &Server{retention: RetentionPolicy{MaxFiles: 2}}
+	removed, err := s.pruneAudioFiles(directory)
+	if err != nil {
+		t.Fatalf("pruneAudioFiles failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected 1 clip removed, got %d", removed)
+	}
+
+	for _, suffix := range []string{".mp3", ".txt", MetadataFileSuffix, TranscriptFileSuffix + ".json"} {
+		if _, err := os.Stat(oldest + suffix); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", oldest+suffix)
+		}
+	}
+	for _, kept := range []string{middle, newest} {
+		if _, err := os.Stat(kept + ".mp3"); err != nil {
+			t.Errorf("expected %s to be kept: %v", kept, err)
+		}
+	}
+}
+
+func TestPruneAudioFilesMaxAge(t *testing.T) {
+	directory := t.TempDir()
+	old := writeClip(t, directory, 1000, time.Now().Add(-48*time.Hour))
+	recent := writeClip(t, directory, 2000, time.Now())
+
+	s := &Server{retention: RetentionPolicy{MaxAge: 24 * time.Hour}}
+	if _, err := s.pruneAudioFiles(directory); err != nil {
+		t.Fatalf("pruneAudioFiles failed: %v", err)
+	}
+
+	if _, err := os.Stat(old + ".mp3"); !os.IsNotExist(err) {
+		t.Error("expected expired clip to be removed")
+	}
+	if _, err := os.Stat(recent + ".mp3"); err != nil {
+		t.Errorf("expected recent clip to be kept: %v", err)
+	}
+}
+
+func TestPruneAudioFilesDisabled(t *testing.T) {
+	directory := t.TempDir()
+	clip := writeClip(t, directory, 1000, time.Now().Add(-time.Hour*24*365))
+
+	s := &Server{}
+	if removed, err := s.pruneAudioFiles(directory); err != nil || removed != 0 {
+		t.Fatalf("expected nothing removed, got %d, %v", removed, err)
+	}
+	if _, err := os.Stat(clip + ".mp3"); err != nil {
+		t.Errorf("expected clip to be kept: %v", err)
+	}
+}
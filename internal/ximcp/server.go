@@ -1,14 +1,12 @@
 package ximcp
 
 import (
-	"context"
 	"fmt"
+	"log"
 	"os"
 	"sync"
 	"time"
 
-	"github.com/gopxl/beep/v2"
-	"github.com/gopxl/beep/v2/speaker"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/taigrr/elevenlabs/client"
 	"github.com/taigrr/elevenlabs/client/types"
@@ -17,7 +15,6 @@ import (
 const (
 	DefaultStability       = 0.5
 	DefaultSimilarityBoost = 0.5
-	AudioDirectory         = ".xi"
 	AudioSampleRate        = 44100
 	RandomHexLength        = 5
 	MaxSummaryWords        = 10
@@ -29,65 +26,155 @@ type Server struct {
 	voices       []types.VoiceResponseModel
 	currentVoice *types.VoiceResponseModel
 	voicesMutex  sync.RWMutex
-	playMutex    sync.Mutex
+	models       []types.ModelResponseModel
+	currentModel string
+	modelsMutex  sync.RWMutex
+
+	// initialVoiceIDs are tried, in order, when voices are first loaded.
+	// The fields below are guarded by voicesMutex.
+	initialVoiceIDs  []string
+	voicesFetchedAt  time.Time
+	voicesRefreshErr error
+	voicesRefreshing bool
+	voiceCacheTTL    time.Duration
+
+	// voiceSettingsCache maps voice IDs to their saved settings, guarded by
+	// voicesMutex.
+	voiceSettingsCache map[string]cachedVoiceSettings
+
+	queue           []*queueItem
+	nextQueueID     uint64
+	currentPlayback *playback
+	queueMutex      sync.Mutex
+	queueCond       *sync.Cond
+	output          AudioOutput
+
+	audioRoot          string
+	cacheRoot          string
+	projectDirectories map[*mcp.ServerSession]string
+	projectsMutex      sync.Mutex
+
+	inlineAudio      bool
+	inlineAudioLimit int64
+
+	// outputFormat is the default format requested from the API.
+	outputFormat string
+
+	// voiceSettings are the default settings, guarded by voicesMutex.
+	voiceSettings    VoiceSettings
+	retention        RetentionPolicy
+	chunkCharacters  int
+	chunkConcurrency int
+
+	stateFile  string
+	stateMutex sync.Mutex
 }
 
-func NewServer() (*mcp.Server, error) {
+func NewServer(config Config) (*Server, error) {
 	apiKey := os.Getenv("XI_API_KEY")
 	if apiKey == "" {
 		return nil, fmt.Errorf("XI_API_KEY environment variable is required")
 	}
 
-	elevenClient := client.New(apiKey)
+	audioRoot, err := resolveAudioDirectory(config.AudioDirectory)
+	if err != nil {
+		return nil, err
+	}
 
-	mcpServer := mcp.NewServer(&mcp.Implementation{
-		Name:    "ElevenLabs MCP Server",
-		Version: "1.0.0",
-	}, nil)
+	if _, err := config.VoiceSettings.apply(defaultSynthesisOptions()); err != nil {
+		return nil, fmt.Errorf("invalid voice settings: %w", err)
+	}
+
+	if err := validateOutputFormat(config.OutputFormat); err != nil {
+		return nil, err
+	}
+
+	state, err := loadState(config.StateFile)
+	if err != nil {
+		log.Printf("Warning: %v; using the configured defaults", err)
+	}
+	voiceIDs, modelID, voiceSettings := startupSelection(config, state)
 
 	s := &Server{
-		client:    elevenClient,
-		mcpServer: mcpServer,
+		client:       client.New(apiKey),
+		currentModel: modelID,
+		audioRoot:    audioRoot,
+		cacheRoot:    DefaultCacheDirectory(),
+		stateFile:    config.StateFile,
+
+		voiceCacheTTL: config.VoiceCacheTTL,
+
+		voiceSettings: voiceSettings,
+		retention:     config.Retention,
+
+		outputFormat:     config.OutputFormat,
+		chunkCharacters:  config.ChunkCharacters,
+		chunkConcurrency: config.ChunkConcurrency,
+		inlineAudio:      config.InlineAudio,
+		inlineAudioLimit: config.MaxInlineAudioBytes,
 	}
 
-	if err := s.initializeVoices(); err != nil {
-		return nil, fmt.Errorf("failed to initialize voices: %w", err)
+	s.mcpServer = mcp.NewServer(&mcp.Implementation{
+		Name:    "ElevenLabs MCP Server",
+		Version: "1.0.0",
+	}, &mcp.ServerOptions{
+		InitializedHandler:      s.sessionInitialized,
+		RootsListChangedHandler: s.rootsListChanged,
+	})
+
+	if err := s.initializeVoices(voiceIDs...); err != nil {
+		log.Printf("Warning: %v; voices will be loaded on first use", err)
 	}
 
-	if err := s.initializeSpeaker(); err != nil {
-		return nil, fmt.Errorf("failed to initialize speaker: %w", err)
+	output, err := newAudioOutput(config.AudioOutput, config.AudioSpeed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize audio output: %w", err)
 	}
+	s.output = output
 
 	s.setupTools()
+	s.setupResources()
 
-	return mcpServer, nil
+	return s, nil
 }
 
-func (s *Server) initializeVoices() error {
-	if err := s.refreshVoices(); err != nil {
-		return err
-	}
-	return nil
-}
+// initializeVoices loads the account's voices and selects the first of
+// voiceIDs that still exists, falling back to the first voice. When the
+// voices cannot be loaded yet, the selection is made once they are.
+func (s *Server) initializeVoices(voiceIDs ...string) error {
+	s.voicesMutex.Lock()
+	s.initialVoiceIDs = voiceIDs
+	s.voicesMutex.Unlock()
 
-func (s *Server) initializeSpeaker() error {
-	sr := beep.SampleRate(AudioSampleRate)
-	speaker.Init(sr, sr.N(time.Second/10))
-	return nil
+	return s.refreshVoices()
 }
 
-func (s *Server) refreshVoices() error {
+func (s *Server) restoreVoice(voiceIDs ...string) {
 	s.voicesMutex.Lock()
 	defer s.voicesMutex.Unlock()
 
-	voices, err := s.client.GetVoices(context.Background())
-	if err != nil {
-		return fmt.Errorf("failed to get voices: %w", err)
+	s.selectFirstVoice(voiceIDs)
+}
+
+// selectFirstVoice selects the first of voiceIDs that exists, keeping the
+// current voice when none do. The caller must hold voicesMutex.
+func (s *Server) selectFirstVoice(voiceIDs []string) {
+	missing := false
+	for _, voiceID := range voiceIDs {
+		if voiceID == "" {
+			continue
+		}
+		if selectedVoice := s.findVoiceByID(voiceID); selectedVoice != nil {
+			s.currentVoice = selectedVoice
+			return
+		}
+		log.Printf("Warning: voice %s is no longer available on this account", voiceID)
+		missing = true
 	}
 
-	s.voices = voices
-	s.reconcileCurrentVoice()
-	return nil
+	if missing && s.currentVoice != nil {
+		log.Printf("Using voice %s (%s)", s.currentVoice.Name, s.currentVoice.VoiceID)
+	}
 }
 
 func (s *Server) reconcileCurrentVoice() {
@@ -98,6 +185,7 @@ func (s *Server) reconcileCurrentVoice() {
 
 	if s.currentVoice == nil {
 		s.currentVoice = &s.voices[0]
+		s.selectFirstVoice(s.initialVoiceIDs)
 		return
 	}
 
@@ -106,31 +194,59 @@ func (s *Server) reconcileCurrentVoice() {
 		return
 	}
 
+	log.Printf("Warning: voice %s was removed from this account, using %s", s.currentVoice.Name, s.voices[0].Name)
 	s.currentVoice = &s.voices[0]
 }
 
-func (s *Server) GetVoices() ([]types.VoiceResponseModel, *types.VoiceResponseModel, error) {
-	if err := s.refreshVoices(); err != nil {
-		return nil, nil, err
+// SetVoice selects the voice used for generation, by ID or name, and
+// remembers it across restarts.
+func (s *Server) SetVoice(voice string) (*types.VoiceResponseModel, error) {
+	if err := s.ensureVoices(); err != nil {
+		return nil, err
 	}
 
-	s.voicesMutex.RLock()
-	defer s.voicesMutex.RUnlock()
+	s.voicesMutex.Lock()
+	selectedVoice, err := s.findVoice(voice)
+	if err == nil {
+		s.currentVoice = selectedVoice
+	}
+	s.voicesMutex.Unlock()
 
-	return s.voices, s.currentVoice, nil
+	if err != nil {
+		return nil, err
+	}
+
+	s.saveState()
+	return selectedVoice, nil
 }
 
-func (s *Server) SetVoice(voiceID string) (*types.VoiceResponseModel, error) {
+// SetVoiceSettings updates the default voice settings with the fields set in
+// settings and remembers them across restarts. It returns the resulting
+// synthesis options.
+func (s *Server) SetVoiceSettings(settings VoiceSettings) (types.SynthesisOptions, error) {
 	s.voicesMutex.Lock()
-	defer s.voicesMutex.Unlock()
+	merged := s.voiceSettings.merge(settings)
+	options, err := merged.apply(defaultSynthesisOptions())
+	if err == nil {
+		s.voiceSettings = merged
+	}
+	s.voicesMutex.Unlock()
 
-	selectedVoice := s.findVoiceByID(voiceID)
-	if selectedVoice == nil {
-		return nil, fmt.Errorf("voice with ID '%s' not found", voiceID)
+	if err != nil {
+		return options, err
 	}
 
-	s.currentVoice = selectedVoice
-	return selectedVoice, nil
+	s.saveState()
+	return options, nil
+}
+
+// applyDefaultSettings applies the server's default voice settings to a
+// voice's saved settings.
+func (s *Server) applyDefaultSettings(saved types.SynthesisOptions) (types.SynthesisOptions, error) {
+	s.voicesMutex.RLock()
+	defer s.voicesMutex.RUnlock()
+
+	return s.voiceSettings.apply(saved)
 }
 
 func (s *Server) findVoiceByID(voiceID string) *types.VoiceResponseModel {
@@ -223,3 +223,108 @@ func TestFormatVoiceListNoSelection(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestFindModelByID(t *testing.T) {
+	s := &Server{
+		models: []types.ModelResponseModel{
+			{ModelID: "eleven_multilingual_v2", Name: "Multilingual v2", CanDoTextToSpeech: true},
+			{ModelID: "eleven_english_sts_v2", Name: "English STS v2"},
+		},
+	}
+
+	if model := s.findModelByID("eleven_multilingual_v2"); model == nil || model.Name != "Multilingual v2" {
+		t.Errorf("expected to find multilingual model, got %+v", model)
+	}
+
+	if model := s.findModelByID("missing"); model != nil {
+		t.Errorf("expected nil for missing model, got %+v", model)
+	}
+}
+
+func TestSetModel(t *testing.T) {
+	s := &Server{
+		models: []types.ModelResponseModel{
+			{ModelID: "eleven_multilingual_v2", Name: "Multilingual v2", CanDoTextToSpeech: true},
+			{ModelID: "eleven_english_sts_v2", Name: "English STS v2"},
+		},
+	}
+
+	t.Run("set valid model", func(t *testing.T) {
+		model, err := s.SetModel("eleven_multilingual_v2")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if model.Name != "Multilingual v2" {
+			t.Errorf("expected 'Multilingual v2', got %q", model.Name)
+		}
+		if s.currentModel != "eleven_multilingual_v2" {
+			t.Error("currentModel not updated")
+		}
+	})
+
+	t.Run("set unknown model", func(t *testing.T) {
+		if _, err := s.SetModel("nonexistent"); err == nil {
+			t.Error("expected error for nonexistent model")
+		}
+	})
+
+	t.Run("set non-TTS model", func(t *testing.T) {
+		_, err := s.SetModel("eleven_english_sts_v2")
+		if err == nil {
+			t.Fatal("expected error for model without text-to-speech")
+		}
+		if !contains(err.Error(), "does not support text-to-speech") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestResolveModelID(t *testing.T) {
+	s := &Server{
+		models: []types.ModelResponseModel{
+			{ModelID: "eleven_flash_v2_5", Name: "Flash v2.5", CanDoTextToSpeech: true},
+			{ModelID: "eleven_english_sts_v2", Name: "English STS v2"},
+		},
+	}
+
+	if modelID, err := s.resolveModelID(""); err != nil || modelID != DefaultModelID {
+		t.Errorf("expected default model, got %q, %v", modelID, err)
+	}
+
+	s.currentModel = "eleven_turbo_v2_5"
+	if modelID, err := s.resolveModelID(" "); err != nil || modelID != "eleven_turbo_v2_5" {
+		t.Errorf("expected current model, got %q, %v", modelID, err)
+	}
+
+	if modelID, err := s.resolveModelID("eleven_flash_v2_5"); err != nil || modelID != "eleven_flash_v2_5" {
+		t.Errorf("expected override model, got %q, %v", modelID, err)
+	}
+
+	if _, err := s.resolveModelID("missing"); err == nil || !contains(err.Error(), "not found") {
+		t.Errorf("expected error for unknown override, got %v", err)
+	}
+	if _, err := s.resolveModelID("eleven_english_sts_v2"); err == nil || !contains(err.Error(), "does not support text-to-speech") {
+		t.Errorf("expected error for non-TTS override, got %v", err)
+	}
+}
+
+func TestFormatModelList(t *testing.T) {
+	s := &Server{}
+
+	models := []types.ModelResponseModel{
+		{ModelID: "eleven_multilingual_v2", Name: "Multilingual v2", CanDoTextToSpeech: true},
+		{ModelID: "eleven_english_sts_v2", Name: "English STS v2"},
+	}
+
+	result := s.formatModelList(models, "eleven_multilingual_v2")
+
+	if !contains(result, "* Multilingual v2 (eleven_multilingual_v2)") {
+		t.Error("expected current model to be marked with asterisk")
+	}
+	if contains(result, "English STS v2") {
+		t.Error("models without text-to-speech should be omitted")
+	}
+	if !contains(result, "Currently selected: eleven_multilingual_v2") {
+		t.Error("expected currently selected line")
+	}
+}
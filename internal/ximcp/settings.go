@@ -0,0 +1,113 @@
+package ximcp
+
+import (
+	"fmt"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const (
+	DefaultStyle           = 0.0
+	DefaultUseSpeakerBoost = false
+	DefaultSpeed           = 1.0
+	MinSpeed               = 0.7
+	MaxSpeed               = 1.2
+)
+
+// SpeechOptions holds the optional per-call overrides shared by say and read.
+type SpeechOptions struct {
+	Voice        string `json:"voice,omitempty" jsonschema:"ID or name of a voice to use for this call only, instead of the currently selected one"`
+	ModelID      string `json:"model_id,omitempty" jsonschema:"ID of the model to use instead of the currently selected one"`
+	OutputFormat string `json:"output_format,omitempty" jsonschema:"Audio format to request, such as mp3_44100_192, pcm_24000 or ulaw_8000 (saved as WAV), or opus_48000_64; defaults to the server's output format"`
+	VoiceSettings
+}
+
+// VoiceSettings holds optional per-call overrides for voice synthesis.
+// Nil fields fall back to the server defaults.
+type VoiceSettings struct {
+	Stability       *float64 `json:"stability,omitempty" jsonschema:"Voice stability from 0 to 1; lower values are more expressive"`
+	SimilarityBoost *float64 `json:"similarity_boost,omitempty" jsonschema:"How closely to match the original voice from 0 to 1"`
+	Style           *float64 `json:"style,omitempty" jsonschema:"Style exaggeration from 0 to 1"`
+	UseSpeakerBoost *bool    `json:"use_speaker_boost,omitempty" jsonschema:"Boost similarity to the original speaker"`
+	Speed           *float64 `json:"speed,omitempty" jsonschema:"Speaking speed from 0.7 to 1.2"`
+}
+
+func defaultSynthesisOptions() types.SynthesisOptions {
+	return types.SynthesisOptions{
+		Stability:       DefaultStability,
+		SimilarityBoost: DefaultSimilarityBoost,
+		Style:           DefaultStyle,
+		UseSpeakerBoost: DefaultUseSpeakerBoost,
+		Speed:           DefaultSpeed,
+	}
+}
+
+func (v VoiceSettings) apply(base types.SynthesisOptions) (types.SynthesisOptions, error) {
+	options := base
+
+	if v.Stability != nil {
+		if err := validateRange("stability", *v.Stability, 0, 1); err != nil {
+			return options, err
+		}
+		options.Stability = *v.Stability
+	}
+
+	if v.SimilarityBoost != nil {
+		if err := validateRange("similarity_boost", *v.SimilarityBoost, 0, 1); err != nil {
+			return options, err
+		}
+		options.SimilarityBoost = *v.SimilarityBoost
+	}
+
+	if v.Style != nil {
+		if err := validateRange("style", *v.Style, 0, 1); err != nil {
+			return options, err
+		}
+		options.Style = *v.Style
+	}
+
+	if v.UseSpeakerBoost != nil {
+		options.UseSpeakerBoost = *v.UseSpeakerBoost
+	}
+
+	if v.Speed != nil {
+		if err := validateRange("speed", *v.Speed, MinSpeed, MaxSpeed); err != nil {
+			return options, err
+		}
+		options.Speed = *v.Speed
+	}
+
+	return options, nil
+}
+
+// merge returns v with the fields set in overrides replaced.
+func (v VoiceSettings) merge(overrides VoiceSettings) VoiceSettings {
+	if overrides.Stability != nil {
+		v.Stability = overrides.Stability
+	}
+	if overrides.SimilarityBoost != nil {
+		v.SimilarityBoost = overrides.SimilarityBoost
+	}
+	if overrides.Style != nil {
+		v.Style = overrides.Style
+	}
+	if overrides.UseSpeakerBoost != nil {
+		v.UseSpeakerBoost = overrides.UseSpeakerBoost
+	}
+	if overrides.Speed != nil {
+		v.Speed = overrides.Speed
+	}
+	return v
+}
+
+func validateRange(name string, value, minValue, maxValue float64) error {
+	if value < minValue || value > maxValue {
+		return fmt.Errorf("%s must be between %g and %g, got %g", name, minValue, maxValue, value)
+	}
+	return nil
+}
+
+func formatSynthesisOptions(options types.SynthesisOptions) string {
+	return fmt.Sprintf("stability=%g, similarity_boost=%g, style=%g, use_speaker_boost=%t, speed=%g",
+		options.Stability, options.SimilarityBoost, options.Style, options.UseSpeakerBoost, options.Speed)
+}
@@ -0,0 +1,91 @@
+package ximcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVoiceSettingsApplyDefaults(t *testing.T) {
+	options, err := VoiceSettings{}.apply(defaultSynthesisOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if options != defaultSynthesisOptions() {
+		t.Errorf("expected defaults to be unchanged, got %+v", options)
+	}
+}
+
+func TestVoiceSettingsApplyOverrides(t *testing.T) {
+	stability := 0.2
+	similarity := 0.9
+	style := 0.4
+	speakerBoost := true
+	speed := 1.1
+
+	options, err := VoiceSettings{
+		Stability:       &stability,
+		SimilarityBoost: &similarity,
+		Style:           &style,
+		UseSpeakerBoost: &speakerBoost,
+		Speed:           &speed,
+	}.apply(defaultSynthesisOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if options.Stability != stability {
+		t.Errorf("expected stability %g, got %g", stability, options.Stability)
+	}
+	if options.SimilarityBoost != similarity {
+		t.Errorf("expected similarity boost %g, got %g", similarity, options.SimilarityBoost)
+	}
+	if options.Style != style {
+		t.Errorf("expected style %g, got %g", style, options.Style)
+	}
+	if !options.UseSpeakerBoost {
+		t.Error("expected speaker boost to be enabled")
+	}
+	if options.Speed != speed {
+		t.Errorf("expected speed %g, got %g", speed, options.Speed)
+	}
+}
+
+func TestVoiceSettingsApplyOutOfRange(t *testing.T) {
+	tooHigh := 1.5
+	tooLow := -0.1
+	tooSlow := 0.5
+
+	tests := []struct {
+		name     string
+		settings VoiceSettings
+		field    string
+	}{
+		{"stability too high", VoiceSettings{Stability: &tooHigh}, "stability"},
+		{"similarity too low", VoiceSettings{SimilarityBoost: &tooLow}, "similarity_boost"},
+		{"style too high", VoiceSettings{Style: &tooHigh}, "style"},
+		{"speed too slow", VoiceSettings{Speed: &tooSlow}, "speed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.settings.apply(defaultSynthesisOptions())
+			if err == nil {
+				t.Fatal("expected range error")
+			}
+			if !strings.HasPrefix(err.Error(), tt.field+" must be between") {
+				t.Errorf("expected error for %s, got: %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestFormatSynthesisOptions(t *testing.T) {
+	result := formatSynthesisOptions(defaultSynthesisOptions())
+
+	for _, expected := range []string{"stability=0.5", "similarity_boost=0.5", "style=0", "use_speaker_boost=false", "speed=1"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("expected %q in %q", expected, result)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ximcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	MinSoundEffectSeconds  = 0.5
+	MaxSoundEffectSeconds  = 30.0
+	DefaultPromptInfluence = 0.3
+	// ClipKindSoundEffect marks clips made by sound generation rather than
+	// text-to-speech in their metadata.
+	ClipKindSoundEffect = "sound_effect"
+)
+
+// SoundEffectOptions holds the optional sound generation parameters.
+type SoundEffectOptions struct {
+	DurationSeconds *float64 `json:"duration_seconds,omitempty" jsonschema:"Length of the sound from 0.5 to 30 seconds; omit to let ElevenLabs choose"`
+	PromptInfluence *float64 `json:"prompt_influence,omitempty" jsonschema:"How closely to follow the prompt from 0 to 1; higher values are less varied (default 0.3)"`
+}
+
+// SoundEffect describes a clip produced by GenerateSoundEffect.
+type SoundEffect struct {
+	FilePath        string
+	PromptInfluence float64
+	// Duration is zero when the saved clip could not be measured.
+	Duration time.Duration
+	// QueueID identifies the playback queue entry when the sound was played.
+	QueueID uint64
+}
+
+// GenerateSoundEffect generates a sound effect from prompt and saves it to
+// the audio directory, with the prompt as its transcript.
+func (s *Server) GenerateSoundEffect(ctx context.Context, prompt string, options SoundEffectOptions) (*SoundEffect, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return nil, fmt.Errorf("prompt is required")
+	}
+
+	var durationSeconds float64
+	if options.DurationSeconds != nil {
+		durationSeconds = *options.DurationSeconds
+		if err := validateRange("duration_seconds", durationSeconds, MinSoundEffectSeconds, MaxSoundEffectSeconds); err != nil {
+			return nil, err
+		}
+	}
+
+	promptInfluence := DefaultPromptInfluence
+	if options.PromptInfluence != nil {
+		promptInfluence = *options.PromptInfluence
+		if err := validateRange("prompt_influence", promptInfluence, 0, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	audioData, err := s.client.SoundGeneration(ctx, prompt, durationSeconds, promptInfluence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate sound effect: %w", err)
+	}
+
+	filePath, err := s.saveAudioFiles(ctx, prompt, audioData, AudioMetadata{
+		Kind:            ClipKindSoundEffect,
+		OutputFormat:    DefaultOutputFormat,
+		PromptInfluence: &promptInfluence,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SoundEffect{
+		FilePath:        filePath,
+		PromptInfluence: promptInfluence,
+		Duration:        s.clipDuration(filePath),
+	}, nil
+}
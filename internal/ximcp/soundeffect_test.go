@@ -0,0 +1,76 @@
+package ximcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client"
+)
+
+func TestGenerateSoundEffect(t *testing.T) {
+	var request map[string]any
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sound-generation" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte("ID3 chime"))
+	}))
+	defer standIn.Close()
+
+	s := &Server{
+		client:    client.New("test-key").WithEndpoint(standIn.URL),
+		audioRoot: t.TempDir(),
+	}
+
+	duration := 1.5
+	effect, err := s.GenerateSoundEffect(context.Background(), "  soft notification chime ", SoundEffectOptions{DurationSeconds: &duration})
+	if err != nil {
+		t.Fatalf("GenerateSoundEffect failed: %v", err)
+	}
+	if request["text"] != "soft notification chime" || request["duration_seconds"] != 1.5 || request["prompt_influence"] != DefaultPromptInfluence {
+		t.Errorf("unexpected request %v", request)
+	}
+	if effect.PromptInfluence != DefaultPromptInfluence || !strings.HasSuffix(effect.FilePath, ".mp3") {
+		t.Errorf("unexpected sound effect %+v", effect)
+	}
+
+	history, err := s.GetAudioHistory(context.Background())
+	if err != nil || len(history) != 1 {
+		t.Fatalf("expected one history entry, got %v, %v", history, err)
+	}
+	if entry := history[0]; entry.Kind != ClipKindSoundEffect || entry.Summary != "soft notification chime" || entry.OutputFormat != DefaultOutputFormat {
+		t.Errorf("unexpected history entry %+v", entry)
+	}
+	if data, err := os.ReadFile(effect.FilePath); err != nil || string(data) != "ID3 chime" {
+		t.Errorf("unexpected saved audio %q, %v", data, err)
+	}
+}
+
+func TestGenerateSoundEffectValidation(t *testing.T) {
+	s := &Server{audioRoot: t.TempDir()}
+	tooLong := MaxSoundEffectSeconds + 1
+	tooStrong := 1.5
+
+	tests := []struct {
+		name    string
+		prompt  string
+		options SoundEffectOptions
+		want    string
+	}{
+		{"empty prompt", " ", SoundEffectOptions{}, "prompt is required"},
+		{"duration", "chime", SoundEffectOptions{DurationSeconds: &tooLong}, "duration_seconds must be between"},
+		{"prompt influence", "chime", SoundEffectOptions{PromptInfluence: &tooStrong}, "prompt_influence must be between"},
+	}
+	for _, tt := range tests {
+		if _, err := s.GenerateSoundEffect(context.Background(), tt.prompt, tt.options); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected %q error, got %v", tt.name, tt.want, err)
+		}
+	}
+}
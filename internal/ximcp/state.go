@@ -0,0 +1,128 @@
+package ximcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const StateFileName = "state.json"
+
+// savedState is the selection remembered across restarts.
+type savedState struct {
+	VoiceID       string        `json:"voice_id,omitempty"`
+	ModelID       string        `json:"model_id,omitempty"`
+	VoiceSettings VoiceSettings `json:"voice_settings"`
+}
+
+// DefaultStateFile returns where the selected voice, model, and settings are
+// remembered, $XDG_STATE_HOME/elevenlabs-mcp/state.json or
+// ~/.local/state/elevenlabs-mcp/state.json.
+func DefaultStateFile() string {
+	if stateHome := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(stateHome) {
+		return filepath.Join(stateHome, DataDirectoryName, StateFileName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", DataDirectoryName, StateFileName)
+	}
+	return ""
+}
+
+// startupSelection returns the voices to try, the model, and the default
+// settings to start with. Values the operator configured win; the remembered
+// state only fills in the rest, field by field for the settings.
+func startupSelection(config Config, state savedState) ([]string, string, VoiceSettings) {
+	modelID := config.ModelID
+	if modelID == "" {
+		modelID = state.ModelID
+	}
+	if modelID == "" {
+		modelID = DefaultModelID
+	}
+	return []string{config.VoiceID, state.VoiceID}, modelID, state.VoiceSettings.merge(config.VoiceSettings)
+}
+
+// loadState reads the state file at path. A missing file is not an error.
+func loadState(path string) (savedState, error) {
+	var state savedState
+	if path == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return savedState{}, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	if _, err := state.VoiceSettings.apply(defaultSynthesisOptions()); err != nil {
+		return savedState{}, fmt.Errorf("invalid voice settings in state file %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// writeState replaces the state file at path, via a rename so a crash never
+// leaves it half written.
+func writeState(path string, state savedState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), StateFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(append(data, '\n')); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
+
+// saveState remembers the current voice, model, and settings for the next
+// start. Failures are logged rather than returned, since the selection has
+// already taken effect for this session.
+func (s *Server) saveState() {
+	if s.stateFile == "" {
+		return
+	}
+
+	var state savedState
+	s.voicesMutex.RLock()
+	if s.currentVoice != nil {
+		state.VoiceID = s.currentVoice.VoiceID
+	}
+	state.VoiceSettings = s.voiceSettings
+	s.voicesMutex.RUnlock()
+
+	s.modelsMutex.RLock()
+	state.ModelID = s.currentModel
+	s.modelsMutex.RUnlock()
+
+	s.stateMutex.Lock()
+	defer s.stateMutex.Unlock()
+
+	if err := writeState(s.stateFile, state); err != nil {
+		log.Printf("Warning: failed to save state: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package ximcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+func TestStatePersistsSelection(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "nested", StateFileName)
+	s := &Server{
+		voices: []types.VoiceResponseModel{
+			{VoiceID: "abc123", Name: "Alice"},
+			{VoiceID: "def456", Name: "Bob"},
+		},
+		models: []types.ModelResponseModel{
+			{ModelID: "eleven_flash_v2_5", Name: "Flash v2.5", CanDoTextToSpeech: true},
+		},
+		stateFile: stateFile,
+	}
+
+	if _, err := s.SetVoice("def456"); err != nil {
+		t.Fatalf("SetVoice failed: %v", err)
+	}
+	if _, err := s.SetModel("eleven_flash_v2_5"); err != nil {
+		t.Fatalf("SetModel failed: %v", err)
+	}
+	stability := 0.3
+	if _, err := s.SetVoiceSettings(VoiceSettings{Stability: &stability}); err != nil {
+		t.Fatalf("SetVoiceSettings failed: %v", err)
+	}
+
+	state, err := loadState(stateFile)
+	if err != nil {
+		t.Fatalf("loadState failed: %v", err)
+	}
+	if state.VoiceID != "def456" || state.ModelID != "eleven_flash_v2_5" {
+		t.Errorf("unexpected state: %+v", state)
+	}
+	if state.VoiceSettings.Stability == nil || *state.VoiceSettings.Stability != 0.3 {
+		t.Errorf("expected stability 0.3 to be saved, got %+v", state.VoiceSettings)
+	}
+}
+
+func TestSetVoiceSettingsMerges(t *testing.T) {
+	stability := 0.3
+	s := &Server{voiceSettings: VoiceSettings{Stability: &stability}}
+
+	speed := 1.1
+	options, err := s.SetVoiceSettings(VoiceSettings{Speed: &speed})
+	if err != nil {
+		t.Fatalf("SetVoiceSettings failed: %v", err)
+	}
+	if options.Stability != 0.3 || options.Speed != 1.1 {
+		t.Errorf("expected earlier settings to be kept, got %+v", options)
+	}
+
+	invalid := 2.0
+	if _, err := s.SetVoiceSettings(VoiceSettings{Speed: &invalid}); err == nil {
+		t.Fatal("expected error for out of range speed")
+	}
+	if *s.voiceSettings.Speed != 1.1 {
+		t.Errorf("invalid settings should not be applied, got speed %g", *s.voiceSettings.Speed)
+	}
+}
+
+func TestLoadState(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		state, err := loadState(filepath.Join(t.TempDir(), StateFileName))
+		if err != nil || state != (savedState{}) {
+			t.Errorf("expected empty state, got %+v, %v", state, err)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		if state, err := loadState(""); err != nil || state != (savedState{}) {
+			t.Errorf("expected empty state, got %+v, %v", state, err)
+		}
+	})
+
+	t.Run("corrupt file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), StateFileName)
+		if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadState(path); err == nil {
+			t.Error("expected error for corrupt state file")
+		}
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), StateFileName)
+		if err := os.WriteFile(path, []byte(`{"voice_settings": {"speed": 5}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadState(path); err == nil {
+			t.Error("expected error for invalid settings")
+		}
+	})
+}
+
+func TestStartupSelection(t *testing.T) {
+	stability, speed, savedSpeed := 0.3, 1.1, 0.9
+	state := savedState{
+		VoiceID:       "def456",
+		ModelID:       "eleven_flash_v2_5",
+		VoiceSettings: VoiceSettings{Stability: &stability, Speed: &savedSpeed},
+	}
+
+	t.Run("state fills unset values", func(t *testing.T) {
+		voiceIDs, modelID, settings := startupSelection(Config{}, state)
+		if voiceIDs[0] != "" || voiceIDs[1] != "def456" || modelID != "eleven_flash_v2_5" {
+			t.Errorf("expected the remembered voice and model, got %v %q", voiceIDs, modelID)
+		}
+		if settings != state.VoiceSettings {
+			t.Errorf("expected the remembered settings, got %+v", settings)
+		}
+	})
+
+	t.Run("configured values win", func(t *testing.T) {
+		config := Config{VoiceID: "abc123", ModelID: "eleven_v3", VoiceSettings: VoiceSettings{Speed: &speed}}
+		voiceIDs, modelID, settings := startupSelection(config, state)
+		if voiceIDs[0] != "abc123" || modelID != "eleven_v3" {
+			t.Errorf("expected the configured voice and model first, got %v %q", voiceIDs, modelID)
+		}
+		if *settings.Speed != 1.1 || *settings.Stability != 0.3 || settings.Style != nil {
+			t.Errorf("expected settings merged field by field, got %+v", settings)
+		}
+	})
+
+	t.Run("default model", func(t *testing.T) {
+		if _, modelID, _ := startupSelection(Config{}, savedState{}); modelID != DefaultModelID {
+			t.Errorf("expected %s, got %q", DefaultModelID, modelID)
+		}
+	})
+}
+
+func TestRestoreVoice(t *testing.T) {
+	newServer := func() *Server {
+		s := &Server{
+			voices: []types.VoiceResponseModel{
+				{VoiceID: "abc123", Name: "Alice"},
+				{VoiceID: "def456", Name: "Bob"},
+			},
+		}
+		s.reconcileCurrentVoice()
+		return s
+	}
+
+	t.Run("restores saved voice", func(t *testing.T) {
+		s := newServer()
+		s.restoreVoice("def456", "abc123")
+		if s.currentVoice.VoiceID != "def456" {
+			t.Errorf("expected saved voice, got %q", s.currentVoice.VoiceID)
+		}
+	})
+
+	t.Run("falls back to configured voice", func(t *testing.T) {
+		s := newServer()
+		s.restoreVoice("deleted", "def456")
+		if s.currentVoice.VoiceID != "def456" {
+			t.Errorf("expected configured voice, got %q", s.currentVoice.VoiceID)
+		}
+	})
+
+	t.Run("falls back to first voice", func(t *testing.T) {
+		s := newServer()
+		s.restoreVoice("deleted", "")
+		if s.currentVoice.VoiceID != "abc123" {
+			t.Errorf("expected first voice, got %q", s.currentVoice.VoiceID)
+		}
+	})
+}
+
+func TestDefaultStateFile(t *testing.T) {
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	expected := filepath.Join(stateHome, DataDirectoryName, StateFileName)
+	if got := DefaultStateFile(); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,215 @@
+package ximcp
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+)
+
+const (
+	prefetchBlockSize = 512
+	prefetchAhead     = time.Second
+)
+
+var errReaderClosed = errors.New("audio buffer reader closed")
+
+// audioBuffer is an append-only in-memory buffer that lets readers consume
+// audio while it is still being written.
+type audioBuffer struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	data   []byte
+	closed bool
+	err    error
+}
+
+func newAudioBuffer() *audioBuffer {
+	buffer := &audioBuffer{}
+	buffer.cond = sync.NewCond(&buffer.mutex)
+	return buffer
+}
+
+func (b *audioBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	b.data = append(b.data, p...)
+	b.cond.Broadcast()
+	return len(p), nil
+}
+
+// CloseWithError marks the buffer complete. Readers drain the remaining data
+// and then receive err, or io.EOF when err is nil.
+func (b *audioBuffer) CloseWithError(err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+	b.err = err
+	b.cond.Broadcast()
+}
+
+// NewReader returns a reader positioned at the start of the buffer that
+// blocks until more data is written or the buffer is closed.
+func (b *audioBuffer) NewReader() io.ReadCloser {
+	return &audioBufferReader{buffer: b}
+}
+
+type audioBufferReader struct {
+	buffer *audioBuffer
+	offset int
+	closed bool
+}
+
+func (r *audioBufferReader) Read(p []byte) (int, error) {
+	buffer := r.buffer
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+
+	for r.offset == len(buffer.data) && !buffer.closed && !r.closed {
+		buffer.cond.Wait()
+	}
+
+	if r.closed {
+		return 0, errReaderClosed
+	}
+
+	if r.offset < len(buffer.data) {
+		n := copy(p, buffer.data[r.offset:])
+		r.offset += n
+		return n, nil
+	}
+
+	if buffer.err != nil {
+		return 0, buffer.err
+	}
+	return 0, io.EOF
+}
+
+// Close stops the reader, waking a Read that is waiting for more data.
+func (r *audioBufferReader) Close() error {
+	r.buffer.mutex.Lock()
+	defer r.buffer.mutex.Unlock()
+
+	r.closed = true
+	r.buffer.cond.Broadcast()
+	return nil
+}
+
+// prefetchStreamer decodes a clip that is still arriving on its own
+// goroutine, so the output never waits on the network while it holds its
+// lock. When the decoded audio runs dry it plays silence until more arrives.
+type prefetchStreamer struct {
+	streamer beep.StreamSeekCloser
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	samples  [][2]float64
+	limit    int
+	position int
+	done     bool
+	closed   bool
+	err      error
+	finished chan struct{}
+}
+
+func newPrefetchStreamer(streamer beep.StreamSeekCloser, format beep.Format) *prefetchStreamer {
+	p := &prefetchStreamer{
+		streamer: streamer,
+		limit:    format.SampleRate.N(prefetchAhead),
+		finished: make(chan struct{}),
+	}
+	p.cond = sync.NewCond(&p.mutex)
+	go p.fill()
+	return p
+}
+
+// fill decodes up to prefetchAhead of audio ahead of playback.
+func (p *prefetchStreamer) fill() {
+	defer close(p.finished)
+
+	block := make([][2]float64, prefetchBlockSize)
+	for {
+		p.mutex.Lock()
+		for len(p.samples) >= p.limit && !p.closed {
+			p.cond.Wait()
+		}
+		closed := p.closed
+		p.mutex.Unlock()
+		if closed {
+			return
+		}
+
+		n, ok := p.streamer.Stream(block)
+
+		p.mutex.Lock()
+		p.samples = append(p.samples, block[:n]...)
+		if !ok {
+			p.done = true
+			p.err = p.streamer.Err()
+		}
+		p.mutex.Unlock()
+		if !ok {
+			return
+		}
+	}
+}
+
+func (p *prefetchStreamer) Stream(samples [][2]float64) (int, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	n := copy(samples, p.samples)
+	p.samples = p.samples[n:]
+	p.position += n
+	p.cond.Broadcast()
+
+	if n == len(samples) || p.done {
+		return n, n > 0
+	}
+	clear(samples[n:])
+	return len(samples), true
+}
+
+func (p *prefetchStreamer) Err() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.err
+}
+
+func (p *prefetchStreamer) Len() int { return 0 }
+
+func (p *prefetchStreamer) Position() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.position
+}
+
+func (p *prefetchStreamer) Seek(int) error {
+	return errors.New("cannot seek streamed audio")
+}
+
+// Close stops decoding and closes the underlying streamer, which also
+// unblocks a decode waiting for more audio.
+func (p *prefetchStreamer) Close() error {
+	p.mutex.Lock()
+	p.closed = true
+	p.cond.Broadcast()
+	p.mutex.Unlock()
+
+	err := p.streamer.Close()
+	<-p.finished
+	return err
+}
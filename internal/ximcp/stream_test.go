@@ -0,0 +1,351 @@
+package ximcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopxl/beep/v2"
+	"github.com/taigrr/elevenlabs/client"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+func TestAudioBufferReadAfterClose(t *testing.T) {
+	buffer := newAudioBuffer()
+	if _, err := buffer.Write([]byte("hello ")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := buffer.Write([]byte("world")); err != nil {
+		t.Fatal(err)
+	}
+	buffer.CloseWithError(nil)
+
+	data, err := io.ReadAll(buffer.NewReader())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data) != "hello world" {
+		t.Errorf("expected full contents, got %q", data)
+	}
+
+	if _, err := buffer.Write([]byte("late")); err == nil {
+		t.Error("expected write after close to fail")
+	}
+}
+
+func TestAudioBufferReaderBlocksUntilWrite(t *testing.T) {
+	buffer := newAudioBuffer()
+	reader := buffer.NewReader()
+
+	received := make(chan string)
+	go func() {
+		p := make([]byte, 16)
+		n, _ := reader.Read(p)
+		received <- string(p[:n])
+	}()
+
+	select {
+	case data := <-received:
+		t.Fatalf("read returned %q before any write", data)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if _, err := buffer.Write([]byte("frame")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-received:
+		if data != "frame" {
+			t.Errorf("expected %q, got %q", "frame", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reader did not wake up after write")
+	}
+}
+
+func TestAudioBufferPropagatesError(t *testing.T) {
+	buffer := newAudioBuffer()
+	if _, err := buffer.Write([]byte("partial")); err != nil {
+		t.Fatal(err)
+	}
+
+	streamErr := errors.New("connection reset")
+	buffer.CloseWithError(streamErr)
+
+	data, err := io.ReadAll(buffer.NewReader())
+	if !errors.Is(err, streamErr) {
+		t.Errorf("expected stream error, got %v", err)
+	}
+	if string(data) != "partial" {
+		t.Errorf("expected buffered data before error, got %q", data)
+	}
+}
+
+func TestPrefetchStreamerPlaysSilenceWhileWaiting(t *testing.T) {
+	buffer := newAudioBuffer()
+	format := beep.Format{SampleRate: 8000, NumChannels: 1, Precision: pcmSampleBytes}
+	streamer := newPrefetchStreamer(newPCMStreamer(buffer.NewReader()), format)
+	defer streamer.Close()
+
+	samples := make([][2]float64, 4)
+	pulled := make(chan int, 1)
+	go func() {
+		n, _ := streamer.Stream(samples)
+		pulled <- n
+	}()
+	select {
+	case n := <-pulled:
+		if n != len(samples) || samples[0] != [2]float64{} {
+			t.Fatalf("expected a block of silence, got %d samples %v", n, samples[:n])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stream blocked waiting for audio")
+	}
+
+	var pcm bytes.Buffer
+	binary.Write(&pcm, binary.LittleEndian, []int16{16384, 16384})
+	if _, err := buffer.Write(pcm.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	buffer.CloseWithError(nil)
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		n, ok := streamer.Stream(samples)
+		if !ok {
+			t.Fatal("stream ended before the audio arrived")
+		}
+		if n == 2 && samples[0][0] == 0.5 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the decoded audio never reached Stream")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n, ok := streamer.Stream(samples); ok || n != 0 {
+		t.Errorf("expected end of stream, got %d, %v", n, ok)
+	}
+	if streamer.Position() != 2 {
+		t.Errorf("expected position 2, got %d", streamer.Position())
+	}
+}
+
+func TestStreamSpeechTeesBeforeDownloadCompletes(t *testing.T) {
+
+	release := make(chan struct{})
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		_, _ = w.Write([]byte("first-frames"))
+		flusher.Flush()
+
+		// Drip the rest only after the test has observed the first bytes.
+		<-release
+		for _, frame := range []string{"-second", "-third"} {
+			_, _ = w.Write([]byte(frame))
+			flusher.Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}))
+	defer standIn.Close()
+	releaseDrip := sync.OnceFunc(func() { close(release) })
+	defer releaseDrip()
+
+	s := &Server{client: client.New("test-key").WithEndpoint(standIn.URL), audioRoot: t.TempDir()}
+	job := &speechJob{
+		speechTarget: speechTarget{
+			voice:   types.VoiceResponseModel{VoiceID: "abc123", Name: "Alice"},
+			options: defaultSynthesisOptions(),
+		},
+		text:    "Hello there",
+		chunks:  []string{"Hello there"},
+		modelID: DefaultModelID,
+	}
+
+	tee := newAudioBuffer()
+	reader := tee.NewReader()
+
+	firstBytes := make(chan string, 1)
+	go func() {
+		p := make([]byte, len("first-frames"))
+		n, _ := io.ReadFull(reader, p)
+		firstBytes <- string(p[:n])
+	}()
+
+	type result struct {
+		filePath string
+		err      error
+	}
+	done := make(chan result, 1)
+	var startedPath string
+	go func() {
+		filePath, err := s.streamSpeech(context.Background(), job, nil, tee, func(filePath string) error {
+			startedPath = filePath
+			return nil
+		})
+		tee.CloseWithError(err)
+		done <- result{filePath, err}
+	}()
+
+	select {
+	case data := <-firstBytes:
+		if data != "first-frames" {
+			t.Fatalf("unexpected first bytes: %q", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no audio reached the tee before the download finished")
+	}
+
+	releaseDrip()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("streamSpeech did not finish")
+	}
+	if res.err != nil {
+		t.Fatalf("streamSpeech failed: %v", res.err)
+	}
+	if res.filePath != startedPath {
+		t.Errorf("started callback saw %q, result was %q", startedPath, res.filePath)
+	}
+
+	content, err := os.ReadFile(res.filePath)
+	if err != nil {
+		t.Fatalf("failed to read audio file: %v", err)
+	}
+	if string(content) != "first-frames-second-third" {
+		t.Errorf("audio file incomplete: %q", content)
+	}
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("unexpected tee error: %v", err)
+	}
+	if string(rest) != "-second-third" {
+		t.Errorf("tee missing later frames: %q", rest)
+	}
+
+	transcript, err := os.ReadFile(strings.TrimSuffix(res.filePath, ".mp3") + ".txt")
+	if err != nil || string(transcript) != "Hello there" {
+		t.Errorf("expected transcript sidecar, got %q (%v)", transcript, err)
+	}
+}
+
+func TestStreamSpeechTeesBeforeLaterChunksComplete(t *testing.T) {
+
+	release := make(chan struct{})
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Text == "Hello there." {
+			_, _ = w.Write([]byte("first-frames"))
+			return
+		}
+
+		// Finish the second chunk only after the test has observed the first.
+		flusher := w.(http.Flusher)
+		_, _ = w.Write([]byte("-second"))
+		flusher.Flush()
+		<-release
+		_, _ = w.Write([]byte("-third"))
+	}))
+	defer standIn.Close()
+	releaseDrip := sync.OnceFunc(func() { close(release) })
+	defer releaseDrip()
+
+	s := &Server{client: client.New("test-key").WithEndpoint(standIn.URL), audioRoot: t.TempDir()}
+	job := &speechJob{
+		speechTarget: speechTarget{
+			voice:   types.VoiceResponseModel{VoiceID: "abc123", Name: "Alice"},
+			options: defaultSynthesisOptions(),
+		},
+		text:    "Hello there. General Kenobi.",
+		chunks:  []string{"Hello there.", "General Kenobi."},
+		modelID: DefaultModelID,
+	}
+
+	tee := newAudioBuffer()
+	reader := tee.NewReader()
+
+	firstBytes := make(chan string, 1)
+	go func() {
+		p := make([]byte, len("first-frames"))
+		n, _ := io.ReadFull(reader, p)
+		firstBytes <- string(p[:n])
+	}()
+
+	type result struct {
+		filePath string
+		err      error
+	}
+	done := make(chan result, 1)
+	var startedPath string
+	go func() {
+		filePath, err := s.streamSpeech(context.Background(), job, nil, tee, func(filePath string) error {
+			startedPath = filePath
+			return nil
+		})
+		tee.CloseWithError(err)
+		done <- result{filePath, err}
+	}()
+
+	select {
+	case data := <-firstBytes:
+		if data != "first-frames" {
+			t.Fatalf("unexpected first bytes: %q", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the first chunk did not reach the tee before the second finished")
+	}
+
+	releaseDrip()
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("streamSpeech did not finish")
+	}
+	if res.err != nil {
+		t.Fatalf("streamSpeech failed: %v", res.err)
+	}
+	if res.filePath != startedPath {
+		t.Errorf("started callback saw %q, result was %q", startedPath, res.filePath)
+	}
+
+	content, err := os.ReadFile(res.filePath)
+	if err != nil {
+		t.Fatalf("failed to read audio file: %v", err)
+	}
+	if string(content) != "first-frames-second-third" {
+		t.Errorf("audio file incomplete: %q", content)
+	}
+
+	rest, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("unexpected tee error: %v", err)
+	}
+	if string(rest) != "-second-third" {
+		t.Errorf("tee missing later frames: %q", rest)
+	}
+
+	transcript, err := os.ReadFile(strings.TrimSuffix(res.filePath, ".mp3") + ".txt")
+	if err != nil || string(transcript) != job.text {
+		t.Errorf("expected transcript sidecar, got %q (%v)", transcript, err)
+	}
+}
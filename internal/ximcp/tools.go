@@ -2,7 +2,9 @@ package ximcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -10,55 +12,295 @@ import (
 )
 
 type SayArgs struct {
-	Text string `json:"text" jsonschema:"Text to convert to speech"`
+	Text        string `json:"text" jsonschema:"Text to convert to speech"`
+	Priority    string `json:"priority,omitempty" jsonschema:"Where to queue playback: append (default), next, or interrupt"`
+	InlineAudio *bool  `json:"inline_audio,omitempty" jsonschema:"Include the generated audio in the result, for clients without access to the server's filesystem"`
+	SpeechOptions
 }
 
 type ReadArgs struct {
-	FilePath string `json:"file_path" jsonschema:"Path to the text file to read and convert to speech"`
+	FilePath    string `json:"file_path" jsonschema:"Path to the text file to read and convert to speech"`
+	InlineAudio *bool  `json:"inline_audio,omitempty" jsonschema:"Include the generated audio in the result, for clients without access to the server's filesystem"`
+	SpeechOptions
+}
+
+type SoundEffectArgs struct {
+	Prompt      string `json:"prompt" jsonschema:"Description of the sound to generate, such as a soft two-note notification chime"`
+	Play        bool   `json:"play,omitempty" jsonschema:"Queue the sound for playback once it is saved"`
+	Priority    string `json:"priority,omitempty" jsonschema:"Where to queue playback when play is set: append (default), next, or interrupt"`
+	InlineAudio *bool  `json:"inline_audio,omitempty" jsonschema:"Include the generated audio in the result, for clients without access to the server's filesystem"`
+	SoundEffectOptions
+}
+
+type TranscribeArgs struct {
+	FilePath string `json:"file_path" jsonschema:"Path to the audio file to transcribe"`
+	TranscribeOptions
+}
+
+type ConvertVoiceArgs struct {
+	Source       string `json:"source" jsonschema:"Path of the recording to convert, or the xi://audio/ URI of a generated clip"`
+	Voice        string `json:"voice,omitempty" jsonschema:"ID or name of the voice to convert to; defaults to the currently selected voice"`
+	ModelID      string `json:"model_id,omitempty" jsonschema:"ID of the speech-to-speech model, eleven_multilingual_sts_v2 by default"`
+	OutputFormat string `json:"output_format,omitempty" jsonschema:"Audio format to request, such as mp3_44100_192 or pcm_24000; defaults to the server's output format"`
+	Play         bool   `json:"play,omitempty" jsonschema:"Queue the converted audio for playback once it is saved"`
+	Priority     string `json:"priority,omitempty" jsonschema:"Where to queue playback when play is set: append (default), next, or interrupt"`
+	InlineAudio  *bool  `json:"inline_audio,omitempty" jsonschema:"Include the converted audio in the result, for clients without access to the server's filesystem"`
+	VoiceSettings
 }
 
 type PlayArgs struct {
 	FilePath string `json:"file_path" jsonschema:"Path to the audio file to play"`
+	Priority string `json:"priority,omitempty" jsonschema:"Where to queue playback: append (default), next, or interrupt"`
+}
+
+type PreviewVoiceArgs struct {
+	Text     string `json:"text,omitempty" jsonschema:"Short sentence to hear in the voice, up to 300 characters; omit to play the voice's stock preview"`
+	Priority string `json:"priority,omitempty" jsonschema:"Where to queue playback: append (default), next, or interrupt"`
+	SpeechOptions
+}
+
+type QueueRemoveArgs struct {
+	ID uint64 `json:"id" jsonschema:"ID of the queued audio to remove, as shown by queue_list"`
 }
 
 type SetVoiceArgs struct {
-	VoiceID string `json:"voice_id" jsonschema:"ID of the voice to use"`
+	VoiceID string `json:"voice_id" jsonschema:"ID or name of the voice to use; names match case-insensitively and partially"`
+	VoiceSettings
+}
+
+type GetVoicesArgs struct {
+	VoiceFilter
+	ForceRefresh bool `json:"force_refresh,omitempty" jsonschema:"Fetch the voice list from ElevenLabs instead of using the cached list"`
+	Limit        int  `json:"limit,omitempty" jsonschema:"Maximum number of voices to return, 25 by default and at most 100"`
+	Offset       int  `json:"offset,omitempty" jsonschema:"Number of matching voices to skip, for fetching later pages"`
+}
+
+type AddVoiceArgs struct {
+	Name        string            `json:"name" jsonschema:"Name for the new voice"`
+	Description string            `json:"description,omitempty" jsonschema:"Description of the new voice"`
+	Labels      map[string]string `json:"labels,omitempty" jsonschema:"Labels such as accent, gender, age, and use_case"`
+	SamplePaths []string          `json:"sample_paths" jsonschema:"Paths of local audio samples of the voice (mp3, wav, m4a, flac, ogg, webm, or aac; up to 25 files of 10 MB each)"`
+	Select      bool              `json:"select,omitempty" jsonschema:"Select the new voice for text-to-speech generation"`
+}
+
+type DeleteVoiceArgs struct {
+	Voice   string `json:"voice" jsonschema:"ID or name of the voice to delete"`
+	Confirm bool   `json:"confirm,omitempty" jsonschema:"Must be true to delete the voice; deletion is permanent"`
+}
+
+type GetVoiceSettingsArgs struct {
+	Voice string `json:"voice,omitempty" jsonschema:"ID or name of the voice; defaults to the currently selected voice"`
+}
+
+type UpdateVoiceSettingsArgs struct {
+	Voice string `json:"voice,omitempty" jsonschema:"ID or name of the voice; defaults to the currently selected voice"`
+	VoiceSettings
+}
+
+type SetModelArgs struct {
+	ModelID string `json:"model_id" jsonschema:"ID of the model to use"`
 }
 
 func (s *Server) setupTools() {
+	destructive := true
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "say",
-		Description: "Convert text to speech, save as MP3 file, and play the audio",
+		Description: "Convert text to speech, save the audio file, and play it",
 	}, s.say)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "read",
-		Description: "Read a text file and convert it to speech, saving as MP3",
+		Description: "Read a text file and convert it to speech, saving the audio file",
 	}, s.read)
 
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "sound_effect",
+		Description: "Generate a sound effect from a text prompt, save the audio file, and optionally play it",
+	}, s.soundEffect)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "convert_voice",
+		Description: "Re-render a recording or generated clip in another voice, keeping its timing and intonation, and save the audio file",
+	}, s.convertVoice)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "transcribe",
+		Description: "Transcribe the speech in a local audio file, optionally with word timestamps and speaker diarization, saving the transcript next to the file",
+	}, s.transcribe)
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "play",
-		Description: "Play an audio file",
+		Description: "Queue an MP3, WAV, FLAC, or Ogg Vorbis audio file for playback",
 	}, s.play)
 
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "queue_list",
+		Description: "List the audio currently playing and the audio waiting in the playback queue",
+	}, s.queueList)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "queue_clear",
+		Description: "Remove all audio waiting in the playback queue without stopping the current audio",
+	}, s.queueClear)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "queue_remove",
+		Description: "Remove a single audio entry from the playback queue",
+	}, s.queueRemove)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "stop",
+		Description: "Stop the current audio and clear the playback queue",
+	}, s.stop)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "pause",
+		Description: "Pause the audio that is currently playing",
+	}, s.pause)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "resume",
+		Description: "Resume paused audio",
+	}, s.resume)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "skip",
+		Description: "Skip the current audio and continue with the next one in the queue",
+	}, s.skip)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "playback_status",
+		Description: "Show the audio file currently playing with its position and duration",
+	}, s.playbackStatus)
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "set_voice",
-		Description: "Set the voice to use for text-to-speech generation",
+		Description: "Set the voice, and optionally the default voice settings, to use for text-to-speech generation. The selection is remembered across restarts",
 	}, s.setVoice)
 
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "preview_voice",
+		Description: "Play a sample of a voice without selecting it or adding to the history: its stock preview, or a short sentence synthesized in that voice",
+	}, s.previewVoice)
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "get_voices",
-		Description: "Get list of available voices and show the currently selected one",
+		Description: "List available voices, optionally filtered by category, labels, or a search term, and show the currently selected one. Results are paginated",
 	}, s.getVoices)
 
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "get_voice_settings",
+		Description: "Show the settings saved on a voice in the ElevenLabs account, which generation uses unless overridden",
+	}, s.getVoiceSettings)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "update_voice_settings",
+		Description: "Change the settings saved on a voice in the ElevenLabs account; fields that are not given keep their saved values",
+	}, s.updateVoiceSettings)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "add_voice",
+		Description: "Create a voice by instant voice cloning from local audio samples",
+	}, s.addVoice)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "delete_voice",
+		Description: "Permanently delete a voice from the ElevenLabs account. Requires confirm to be true",
+		Annotations: &mcp.ToolAnnotations{DestructiveHint: &destructive},
+	}, s.deleteVoice)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "set_model",
+		Description: "Set the model to use for text-to-speech generation. The selection is remembered across restarts",
+	}, s.setModel)
+
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "list_models",
+		Description: "Get list of available text-to-speech models and show the currently selected one",
+	}, s.listModels)
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "history",
 		Description: "List available audio files with text summaries",
 	}, s.history)
 }
 
-func (s *Server) say(ctx context.Context, req *mcp.CallToolRequest, args SayArgs) (*mcp.CallToolResult, any, error) {
-	filepath, err := s.GenerateAudio(args.Text)
+func (s *Server) say(ctx context.Context, req *mcp.CallToolRequest, args SayArgs) (*mcp.CallToolResult, *SpeechResult, error) {
+	priority, err := parseQueuePriority(args.Priority)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	ctx = s.projectContext(ctx, req)
+	audio, err := s.StreamAudio(ctx, args.Text, args.SpeechOptions, priority, progressNotifier(ctx, req))
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	message := fmt.Sprintf("Audio generated with %s, queued for streaming playback, and saved to %s (%s)",
+		audio.ModelID, audio.FilePath, formatSynthesisOptions(audio.Settings))
+	if audio.QueueID == 0 {
+		message = fmt.Sprintf("Audio generated with %s and saved to %s (%s), but not played: %s clips are saved but cannot be played here",
+			audio.ModelID, audio.FilePath, formatSynthesisOptions(audio.Settings), audio.OutputFormat)
+	}
+	content := []mcp.Content{
+		&mcp.TextContent{Text: message},
+	}
+	content, err = s.appendInlineAudio(content, audio.FilePath, args.InlineAudio)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{Content: content}, newSpeechResult(audio), nil
+}
+
+func (s *Server) read(ctx context.Context, req *mcp.CallToolRequest, args ReadArgs) (*mcp.CallToolResult, *SpeechResult, error) {
+	ctx = s.projectContext(ctx, req)
+	audio, err := s.ReadFileToAudio(ctx, args.FilePath, args.SpeechOptions, progressNotifier(ctx, req))
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	content := []mcp.Content{
+		&mcp.TextContent{Text: fmt.Sprintf("File '%s' converted to speech in %d chunk(s) with %s and saved to: %s (%s)",
+			args.FilePath, audio.Chunks, audio.ModelID, audio.FilePath, formatSynthesisOptions(audio.Settings))},
+	}
+	content, err = s.appendInlineAudio(content, audio.FilePath, args.InlineAudio)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{Content: content}, newSpeechResult(audio), nil
+}
+
+func (s *Server) soundEffect(ctx context.Context, req *mcp.CallToolRequest, args SoundEffectArgs) (*mcp.CallToolResult, *SoundEffectResult, error) {
+	priority, err := parseQueuePriority(args.Priority)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -68,17 +310,184 @@ func (s *Server) say(ctx context.Context, req *mcp.CallToolRequest, args SayArgs
 		}, nil, nil
 	}
 
-	s.PlayAudioAsync(filepath)
+	ctx = s.projectContext(ctx, req)
+	effect, err := s.GenerateSoundEffect(ctx, args.Prompt, args.SoundEffectOptions)
+	if err == nil && args.Play {
+		var entry *QueueEntry
+		if entry, err = s.EnqueueAudio(effect.FilePath, priority); err == nil {
+			effect.QueueID = entry.ID
+		}
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	message := fmt.Sprintf("Sound effect saved to %s", effect.FilePath)
+	if effect.QueueID != 0 {
+		message = fmt.Sprintf("Sound effect saved to %s and queued for playback [%d]", effect.FilePath, effect.QueueID)
+	}
+	content := []mcp.Content{
+		&mcp.TextContent{Text: message},
+	}
+	content, err = s.appendInlineAudio(content, effect.FilePath, args.InlineAudio)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{Content: content}, &SoundEffectResult{
+		FilePath:        effect.FilePath,
+		PromptInfluence: effect.PromptInfluence,
+		DurationSeconds: effect.Duration.Seconds(),
+		QueueID:         effect.QueueID,
+	}, nil
+}
+
+func (s *Server) convertVoice(ctx context.Context, req *mcp.CallToolRequest, args ConvertVoiceArgs) (*mcp.CallToolResult, *ConvertVoiceResult, error) {
+	priority, err := parseQueuePriority(args.Priority)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	ctx = s.projectContext(ctx, req)
+	audio, err := s.ConvertVoice(ctx, args.Source, SpeechOptions{
+		Voice:         args.Voice,
+		ModelID:       args.ModelID,
+		OutputFormat:  args.OutputFormat,
+		VoiceSettings: args.VoiceSettings,
+	})
+	var playbackNote string
+	if err == nil && args.Play {
+		var entry *QueueEntry
+		if entry, err = s.EnqueueAudio(audio.FilePath, priority); err == nil {
+			audio.QueueID = entry.ID
+		} else if errors.Is(err, errOpusPlayback) {
+			playbackNote = fmt.Sprintf("; not played: %s clips are saved but cannot be played here", audio.OutputFormat)
+			err = nil
+		}
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	message := fmt.Sprintf("Converted %s to %s with %s and saved to %s (%s)",
+		audio.SourceFile, audio.VoiceName, audio.ModelID, audio.FilePath, formatSynthesisOptions(audio.Settings))
+	if audio.QueueID != 0 {
+		message += fmt.Sprintf("; queued for playback [%d]", audio.QueueID)
+	}
+	message += playbackNote
+	content := []mcp.Content{
+		&mcp.TextContent{Text: message},
+	}
+	content, err = s.appendInlineAudio(content, audio.FilePath, args.InlineAudio)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{Content: content}, newConvertVoiceResult(audio), nil
+}
+
+func (s *Server) transcribe(ctx context.Context, req *mcp.CallToolRequest, args TranscribeArgs) (*mcp.CallToolResult, *TranscriptResult, error) {
+	transcript, err := s.Transcribe(ctx, args.FilePath, args.TranscribeOptions)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("Audio generated, saved to %s, and playing", filepath)},
+			&mcp.TextContent{Text: fmt.Sprintf("Transcript of %s (saved to %s):\n\n%s", transcript.FilePath, transcript.TextFilePath, transcript.Text)},
+		},
+	}, newTranscriptResult(transcript), nil
+}
+
+func (s *Server) play(ctx context.Context, req *mcp.CallToolRequest, args PlayArgs) (*mcp.CallToolResult, *PlayResult, error) {
+	priority, err := parseQueuePriority(args.Priority)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	entry, err := s.EnqueueAudio(args.FilePath, priority)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	result := &PlayResult{
+		QueueID:  entry.ID,
+		FilePath: args.FilePath,
+		Priority: string(priority),
+	}
+	if duration, err := audioDuration(args.FilePath); err == nil {
+		result.DurationSeconds = duration.Seconds()
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Queued audio file [%d]: %s", entry.ID, args.FilePath)},
+		},
+	}, result, nil
+}
+
+func (s *Server) queueList(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	current, pending := s.QueueStatus()
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: formatQueue(current, pending)},
+		},
+	}, nil, nil
+}
+
+func (s *Server) queueClear(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	removed := s.ClearQueue()
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Removed %d queued audio file(s)", removed)},
 		},
 	}, nil, nil
 }
 
-func (s *Server) read(ctx context.Context, req *mcp.CallToolRequest, args ReadArgs) (*mcp.CallToolResult, any, error) {
-	audioPath, err := s.ReadFileToAudio(args.FilePath)
+func (s *Server) queueRemove(ctx context.Context, req *mcp.CallToolRequest, args QueueRemoveArgs) (*mcp.CallToolResult, any, error) {
+	entry, err := s.RemoveFromQueue(args.ID)
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -90,13 +499,30 @@ func (s *Server) read(ctx context.Context, req *mcp.CallToolRequest, args ReadAr
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("File '%s' converted to speech and saved to: %s", args.FilePath, audioPath)},
+			&mcp.TextContent{Text: fmt.Sprintf("Removed [%d] %s from the queue", entry.ID, entry.Name)},
 		},
 	}, nil, nil
 }
 
-func (s *Server) play(ctx context.Context, req *mcp.CallToolRequest, args PlayArgs) (*mcp.CallToolResult, any, error) {
-	if err := validateAudioFilePath(args.FilePath); err != nil {
+func (s *Server) stop(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	return playbackControlResult(s.StopPlayback, "Stopped playback of %s")
+}
+
+func (s *Server) pause(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	return playbackControlResult(s.PausePlayback, "Paused %s")
+}
+
+func (s *Server) resume(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	return playbackControlResult(s.ResumePlayback, "Resumed %s")
+}
+
+func (s *Server) skip(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	return playbackControlResult(s.SkipPlayback, "Skipped %s")
+}
+
+func playbackControlResult(control func() (string, error), format string) (*mcp.CallToolResult, any, error) {
+	name, err := control()
+	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
@@ -105,17 +531,49 @@ func (s *Server) play(ctx context.Context, req *mcp.CallToolRequest, args PlayAr
 		}, nil, nil
 	}
 
-	s.PlayAudioAsync(args.FilePath)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf(format, name)},
+		},
+	}, nil, nil
+}
+
+func (s *Server) playbackStatus(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	status, err := s.PlaybackStatus()
+	if err != nil {
+		if errors.Is(err, errNothingPlaying) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: "Nothing is playing"},
+				},
+			}, nil, nil
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("Playing audio file: %s", args.FilePath)},
+			&mcp.TextContent{Text: formatPlaybackStatus(status)},
 		},
 	}, nil, nil
 }
 
-func (s *Server) setVoice(ctx context.Context, req *mcp.CallToolRequest, args SetVoiceArgs) (*mcp.CallToolResult, any, error) {
-	selectedVoice, err := s.SetVoice(args.VoiceID)
+func (s *Server) setVoice(ctx context.Context, req *mcp.CallToolRequest, args SetVoiceArgs) (*mcp.CallToolResult, *SetVoiceResult, error) {
+	// Validate the settings first so a bad value leaves the voice unchanged.
+	_, err := args.VoiceSettings.apply(defaultSynthesisOptions())
+	var selectedVoice *types.VoiceResponseModel
+	if err == nil {
+		selectedVoice, err = s.SetVoice(args.VoiceID)
+	}
+	var options types.SynthesisOptions
+	if err == nil {
+		options, err = s.SetVoiceSettings(args.VoiceSettings)
+	}
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -125,15 +583,38 @@ func (s *Server) setVoice(ctx context.Context, req *mcp.CallToolRequest, args Se
 		}, nil, nil
 	}
 
+	text := fmt.Sprintf("Voice set to: %s (%s)", selectedVoice.Name, selectedVoice.VoiceID)
+	if args.VoiceSettings != (VoiceSettings{}) {
+		text += fmt.Sprintf("\nDefault settings: %s", formatSynthesisOptions(options))
+	}
+
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: fmt.Sprintf("Voice set to: %s (%s)", selectedVoice.Name, selectedVoice.VoiceID)},
+			&mcp.TextContent{Text: text},
+		},
+	}, &SetVoiceResult{VoiceID: selectedVoice.VoiceID, Name: selectedVoice.Name, Settings: options}, nil
+}
+
+func (s *Server) setModel(ctx context.Context, req *mcp.CallToolRequest, args SetModelArgs) (*mcp.CallToolResult, any, error) {
+	selectedModel, err := s.SetModel(args.ModelID)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Model set to: %s (%s)", selectedModel.Name, selectedModel.ModelID)},
 		},
 	}, nil, nil
 }
 
-func (s *Server) getVoices(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
-	voices, currentVoice, err := s.GetVoices()
+func (s *Server) listModels(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	models, currentModelID, err := s.GetModels()
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -143,15 +624,241 @@ func (s *Server) getVoices(ctx context.Context, req *mcp.CallToolRequest, args s
 		}, nil, nil
 	}
 
-	voiceList := s.formatVoiceList(voices, currentVoice)
+	modelList := s.formatModelList(models, currentModelID)
 
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: voiceList},
+			&mcp.TextContent{Text: modelList},
 		},
 	}, nil, nil
 }
 
+func (s *Server) getVoices(ctx context.Context, req *mcp.CallToolRequest, args GetVoicesArgs) (*mcp.CallToolResult, *GetVoicesResult, error) {
+	voices, currentVoice, err := s.GetVoices(args.ForceRefresh)
+	var staleErr *StaleVoicesError
+	if errors.As(err, &staleErr) {
+		err = nil
+	}
+	var start, end int
+	if err == nil {
+		voices = filterVoices(voices, args.VoiceFilter)
+		start, end, err = pageBounds(len(voices), args.Offset, args.Limit)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	result := newGetVoicesResult(voices[start:end], currentVoice)
+	result.Total = len(voices)
+	result.Offset = start
+	if end < len(voices) {
+		result.NextOffset = end
+	}
+
+	voiceList := s.formatVoiceList(voices[start:end], currentVoice)
+	switch {
+	case len(voices) == 0:
+		voiceList = "No voices match the given filters\n" + voiceList
+	case start == end:
+		voiceList += fmt.Sprintf("\nOffset %d is past the last of %d matching voices", start, len(voices))
+	case start > 0 || end < len(voices):
+		voiceList += fmt.Sprintf("\nShowing voices %d-%d of %d", start+1, end, len(voices))
+		if end < len(voices) {
+			voiceList += fmt.Sprintf("; pass offset %d for more", end)
+		}
+	}
+
+	if staleErr != nil {
+		result.Warning = staleErr.Error()
+		voiceList = fmt.Sprintf("Warning: %v\n\n%s", staleErr, voiceList)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: voiceList},
+		},
+	}, result, nil
+}
+
+func (s *Server) previewVoice(ctx context.Context, req *mcp.CallToolRequest, args PreviewVoiceArgs) (*mcp.CallToolResult, *PreviewVoiceResult, error) {
+	priority, err := parseQueuePriority(args.Priority)
+	var preview *VoicePreview
+	if err == nil {
+		preview, err = s.PreviewVoice(ctx, args.Text, args.SpeechOptions)
+	}
+	var entry *QueueEntry
+	if err == nil {
+		entry, err = s.EnqueueAudio(preview.FilePath, priority)
+	}
+	if errors.Is(err, errOpusPlayback) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Saved the %s sample of %s (%s) to %s, but not played: Ogg Opus clips are saved but cannot be played here", preview.Source, preview.Voice.Name, preview.Voice.VoiceID, preview.FilePath)},
+			},
+		}, &PreviewVoiceResult{
+			VoiceID:   preview.Voice.VoiceID,
+			VoiceName: preview.Voice.Name,
+			Source:    preview.Source,
+			FilePath:  preview.FilePath,
+			Cached:    preview.Cached,
+		}, nil
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	result := &PreviewVoiceResult{
+		VoiceID:   preview.Voice.VoiceID,
+		VoiceName: preview.Voice.Name,
+		Source:    preview.Source,
+		FilePath:  preview.FilePath,
+		Cached:    preview.Cached,
+		QueueID:   entry.ID,
+	}
+	if duration, err := audioDuration(preview.FilePath); err == nil {
+		result.DurationSeconds = duration.Seconds()
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Previewing %s (%s) with its %s sample (queue ID %d)", preview.Voice.Name, preview.Voice.VoiceID, preview.Source, entry.ID)},
+		},
+	}, result, nil
+}
+
+func (s *Server) getVoiceSettings(ctx context.Context, req *mcp.CallToolRequest, args GetVoiceSettingsArgs) (*mcp.CallToolResult, *VoiceSettingsResult, error) {
+	voice, options, err := s.GetVoiceSettings(ctx, args.Voice)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Saved settings for %s (%s): %s", voice.Name, voice.VoiceID, formatSynthesisOptions(options))},
+		},
+	}, &VoiceSettingsResult{VoiceID: voice.VoiceID, Name: voice.Name, Settings: options}, nil
+}
+
+func (s *Server) updateVoiceSettings(ctx context.Context, req *mcp.CallToolRequest, args UpdateVoiceSettingsArgs) (*mcp.CallToolResult, *VoiceSettingsResult, error) {
+	voice, options, err := s.UpdateVoiceSettings(ctx, args.Voice, args.VoiceSettings)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Updated settings for %s (%s): %s", voice.Name, voice.VoiceID, formatSynthesisOptions(options))},
+		},
+	}, &VoiceSettingsResult{VoiceID: voice.VoiceID, Name: voice.Name, Settings: options}, nil
+}
+
+func (s *Server) addVoice(ctx context.Context, req *mcp.CallToolRequest, args AddVoiceArgs) (*mcp.CallToolResult, *AddVoiceResult, error) {
+	voice, err := s.AddVoice(ctx, args.Name, args.Description, args.Labels, args.SamplePaths)
+	if err == nil && args.Select {
+		voice, err = s.SetVoice(voice.VoiceID)
+	}
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	text := fmt.Sprintf("Created voice: %s (%s)", voice.Name, voice.VoiceID)
+	if args.Select {
+		text += "\nVoice selected for generation"
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: text},
+		},
+	}, &AddVoiceResult{VoiceID: voice.VoiceID, Name: voice.Name, Selected: args.Select}, nil
+}
+
+func (s *Server) deleteVoice(ctx context.Context, req *mcp.CallToolRequest, args DeleteVoiceArgs) (*mcp.CallToolResult, *DeleteVoiceResult, error) {
+	if !args.Confirm {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: deleting %s is permanent; call delete_voice again with confirm set to true", args.Voice)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	voice, err := s.DeleteVoice(ctx, args.Voice)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: fmt.Sprintf("Error: %v", err)},
+			},
+			IsError: true,
+		}, nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Deleted voice: %s (%s)", voice.Name, voice.VoiceID)},
+		},
+	}, &DeleteVoiceResult{VoiceID: voice.VoiceID, Name: voice.Name}, nil
+}
+
+// appendInlineAudio adds the generated clip to content when inline audio is
+// enabled for the call.
+func (s *Server) appendInlineAudio(content []mcp.Content, filePath string, inline *bool) ([]mcp.Content, error) {
+	audioContent, err := s.inlineAudioContent(filePath, inline)
+	if err != nil || audioContent == nil {
+		return content, err
+	}
+	return append(content, audioContent), nil
+}
+
+// progressNotifier reports chunk progress to the client when the request carries a progress token.
+func progressNotifier(ctx context.Context, req *mcp.CallToolRequest) ProgressFunc {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+
+	progressToken := req.Params.GetProgressToken()
+	if progressToken == nil {
+		return nil
+	}
+
+	return func(completed, total int) {
+		err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: progressToken,
+			Progress:      float64(completed),
+			Total:         float64(total),
+			Message:       fmt.Sprintf("Synthesized chunk %d of %d", completed, total),
+		})
+		if err != nil {
+			log.Printf("Error sending progress notification: %v", err)
+		}
+	}
+}
+
 func (s *Server) formatVoiceList(voices []types.VoiceResponseModel, currentVoice *types.VoiceResponseModel) string {
 	var voiceList strings.Builder
 	voiceList.WriteString("Available voices:\n")
@@ -161,8 +868,8 @@ func (s *Server) formatVoiceList(voices []types.VoiceResponseModel, currentVoice
 		if currentVoice != nil && voice.VoiceID == currentVoice.VoiceID {
 			marker = "* "
 		}
-		voiceList.WriteString(fmt.Sprintf("%s%s (%s) - %s\n",
-			marker, voice.Name, voice.VoiceID, voice.Category))
+		voiceList.WriteString(fmt.Sprintf("%s%s (%s) - %s%s\n",
+			marker, voice.Name, voice.VoiceID, voice.Category, formatVoiceLabels(voice)))
 	}
 
 	if currentVoice != nil {
@@ -175,8 +882,8 @@ func (s *Server) formatVoiceList(voices []types.VoiceResponseModel, currentVoice
 	return voiceList.String()
 }
 
-func (s *Server) history(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
-	audioFiles, err := s.GetAudioHistory()
+func (s *Server) history(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, *HistoryResult, error) {
+	audioFiles, err := s.GetAudioHistory(s.projectContext(ctx, req))
 	if err != nil {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{
@@ -191,7 +898,7 @@ func (s *Server) history(ctx context.Context, req *mcp.CallToolRequest, args str
 			Content: []mcp.Content{
 				&mcp.TextContent{Text: "No audio files found"},
 			},
-		}, nil, nil
+		}, newHistoryResult(audioFiles), nil
 	}
 
 	historyList := s.formatHistoryList(audioFiles)
@@ -200,7 +907,7 @@ func (s *Server) history(ctx context.Context, req *mcp.CallToolRequest, args str
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: historyList},
 		},
-	}, nil, nil
+	}, newHistoryResult(audioFiles), nil
 }
 
 func (s *Server) formatHistoryList(audioFiles []AudioFile) string {
@@ -208,7 +915,24 @@ func (s *Server) formatHistoryList(audioFiles []AudioFile) string {
 	historyList.WriteString("Available audio files:\n\n")
 
 	for _, audioFile := range audioFiles {
-		historyList.WriteString(fmt.Sprintf("• %s\n  %s\n\n", audioFile.Name, audioFile.Summary))
+		historyList.WriteString(fmt.Sprintf("• %s\n  %s\n", audioFile.Name, audioFile.Summary))
+		switch audioFile.Kind {
+		case ClipKindSoundEffect:
+			historyList.WriteString("  sound effect\n")
+		case ClipKindVoiceConversion:
+			source := audioFile.SourceFile
+			if audioFile.SourceVoiceName != "" {
+				source = fmt.Sprintf("%s (%s)", source, audioFile.SourceVoiceName)
+			}
+			historyList.WriteString(fmt.Sprintf("  converted from %s to %s\n", source, audioFile.VoiceName))
+		}
+		if audioFile.ModelID != "" {
+			historyList.WriteString(fmt.Sprintf("  model: %s\n", audioFile.ModelID))
+		}
+		if audioFile.OutputFormat != "" {
+			historyList.WriteString(fmt.Sprintf("  format: %s\n", audioFile.OutputFormat))
+		}
+		historyList.WriteString("\n")
 	}
 
 	return historyList.String()
@@ -0,0 +1,152 @@
+package ximcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const (
+	MaxTranscriptionSpeakers = 32
+	// TranscriptFileSuffix is added to the audio file's base name for the
+	// transcript sidecars, so they don't replace the source text of a clip.
+	TranscriptFileSuffix = ".transcript"
+	transcriptWordType   = "word"
+	transcriptSpacing    = "spacing"
+)
+
+// TranscribeOptions holds the optional speech-to-text parameters.
+type TranscribeOptions struct {
+	LanguageCode string `json:"language_code,omitempty" jsonschema:"ISO-639 code of the spoken language; detected automatically when omitted"`
+	Timestamps   bool   `json:"timestamps,omitempty" jsonschema:"Include each word with its start and end time"`
+	Diarize      bool   `json:"diarize,omitempty" jsonschema:"Identify who is speaking; the transcript is split into speaker turns and words carry speaker IDs"`
+	NumSpeakers  int    `json:"num_speakers,omitempty" jsonschema:"Maximum number of speakers in the audio, up to 32, to help diarization"`
+}
+
+// TranscriptWord is a word of a transcript with its timing in seconds.
+type TranscriptWord struct {
+	Text      string  `json:"text"`
+	Start     float64 `json:"start"`
+	End       float64 `json:"end"`
+	SpeakerID string  `json:"speaker_id,omitempty"`
+}
+
+// Transcript is the result of Transcribe, also saved as its JSON sidecar.
+type Transcript struct {
+	FilePath            string           `json:"file_path"`
+	Text                string           `json:"text"`
+	LanguageCode        string           `json:"language_code,omitempty"`
+	LanguageProbability float64          `json:"language_probability,omitempty"`
+	Words               []TranscriptWord `json:"words,omitempty"`
+	TextFilePath        string           `json:"-"`
+	JSONFilePath        string           `json:"-"`
+}
+
+// Transcribe converts the speech in a local audio file to text and saves the
+// transcript next to it as <name>.transcript.txt and <name>.transcript.json.
+func (s *Server) Transcribe(ctx context.Context, filePath string, options TranscribeOptions) (*Transcript, error) {
+	if err := validateAudioFilePath(filePath); err != nil {
+		return nil, err
+	}
+	if err := validateRange("num_speakers", float64(options.NumSpeakers), 0, MaxTranscriptionSpeakers); err != nil {
+		return nil, err
+	}
+
+	request := types.SpeechToTextRequest{
+		ModelID:               types.SpeechToTextModelScribeV1,
+		LanguageCode:          strings.TrimSpace(options.LanguageCode),
+		NumSpeakers:           options.NumSpeakers,
+		TimestampsGranularity: types.TimestampsGranularityNone,
+		Diarize:               options.Diarize,
+	}
+	if options.Timestamps || options.Diarize {
+		request.TimestampsGranularity = types.TimestampsGranularityWord
+	}
+
+	response, err := s.client.ConvertSpeechToText(ctx, filePath, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcribe audio: %w", err)
+	}
+
+	transcript := &Transcript{
+		FilePath:            filePath,
+		Text:                strings.TrimSpace(response.Text),
+		LanguageCode:        response.LanguageCode,
+		LanguageProbability: response.LanguageProbability,
+	}
+	if options.Diarize {
+		if turns := speakerTurns(response.Words); turns != "" {
+			transcript.Text = turns
+		}
+	}
+	if options.Timestamps || options.Diarize {
+		for _, word := range response.Words {
+			if word.Type == transcriptWordType {
+				transcript.Words = append(transcript.Words, TranscriptWord{
+					Text:      word.Text,
+					Start:     word.Start,
+					End:       word.End,
+					SpeakerID: word.SpeakerID,
+				})
+			}
+		}
+	}
+
+	if err := writeTranscriptFiles(transcript); err != nil {
+		return nil, err
+	}
+	return transcript, nil
+}
+
+// speakerTurns renders diarized words as one "speaker: text" line per turn.
+func speakerTurns(words []types.TranscriptionWord) string {
+	var turns []string
+	var current strings.Builder
+	speaker := ""
+	flush := func() {
+		text := strings.TrimSpace(current.String())
+		current.Reset()
+		if text == "" {
+			return
+		}
+		if speaker != "" {
+			text = fmt.Sprintf("%s: %s", speaker, text)
+		}
+		turns = append(turns, text)
+	}
+
+	for _, word := range words {
+		if word.Type != transcriptSpacing && word.SpeakerID != speaker {
+			flush()
+			speaker = word.SpeakerID
+		}
+		current.WriteString(word.Text)
+	}
+	flush()
+
+	return strings.Join(turns, "\n")
+}
+
+func writeTranscriptFiles(transcript *Transcript) error {
+	base := strings.TrimSuffix(transcript.FilePath, filepath.Ext(transcript.FilePath)) + TranscriptFileSuffix
+	transcript.TextFilePath = base + ".txt"
+	transcript.JSONFilePath = base + ".json"
+
+	if err := os.WriteFile(transcript.TextFilePath, []byte(transcript.Text+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write transcript file: %w", err)
+	}
+
+	data, err := json.MarshalIndent(transcript, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode transcript: %w", err)
+	}
+	if err := os.WriteFile(transcript.JSONFilePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write transcript file: %w", err)
+	}
+	return nil
+}
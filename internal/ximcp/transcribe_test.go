@@ -0,0 +1,125 @@
+package ximcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+var diarizedWords = []types.TranscriptionWord{
+	{Text: "Hello", Type: "word", Start: 0, End: 0.4, SpeakerID: "speaker_0"},
+	{Text: " ", Type: "spacing", Start: 0.4, End: 0.5, SpeakerID: "speaker_0"},
+	{Text: "there.", Type: "word", Start: 0.5, End: 0.9, SpeakerID: "speaker_0"},
+	{Text: " ", Type: "spacing", Start: 0.9, End: 1.2, SpeakerID: "speaker_0"},
+	{Text: "Hi!", Type: "word", Start: 1.2, End: 1.5, SpeakerID: "speaker_1"},
+}
+
+func newTranscribeServer(t *testing.T, form map[string]string) *Server {
+	t.Helper()
+
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/speech-to-text" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for key, values := range r.MultipartForm.Value {
+			form[key] = values[0]
+		}
+		json.NewEncoder(w).Encode(types.SpeechToTextResponse{
+			LanguageCode:        "eng",
+			LanguageProbability: 0.98,
+			Text:                "Hello there. Hi!",
+			Words:               diarizedWords,
+		})
+	}))
+	t.Cleanup(standIn.Close)
+
+	return &Server{client: client.New("test-key").WithEndpoint(standIn.URL)}
+}
+
+func TestTranscribeSavesSidecars(t *testing.T) {
+	form := make(map[string]string)
+	s := newTranscribeServer(t, form)
+	filePath := filepath.Join(t.TempDir(), "meeting.flac")
+	if err := os.WriteFile(filePath, []byte("fLaC"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript, err := s.Transcribe(context.Background(), filePath, TranscribeOptions{})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if form["model_id"] != "scribe_v1" || form["timestamps_granularity"] != "none" || form["diarize"] != "false" {
+		t.Errorf("unexpected request form %v", form)
+	}
+	if transcript.Text != "Hello there. Hi!" || transcript.LanguageCode != "eng" || transcript.Words != nil {
+		t.Errorf("unexpected transcript %+v", transcript)
+	}
+
+	base := strings.TrimSuffix(filePath, ".flac") + TranscriptFileSuffix
+	if transcript.TextFilePath != base+".txt" || transcript.JSONFilePath != base+".json" {
+		t.Errorf("unexpected sidecar paths %s, %s", transcript.TextFilePath, transcript.JSONFilePath)
+	}
+	if data, err := os.ReadFile(transcript.TextFilePath); err != nil || string(data) != "Hello there. Hi!\n" {
+		t.Errorf("unexpected text sidecar %q, %v", data, err)
+	}
+
+	var saved Transcript
+	data, err := os.ReadFile(transcript.JSONFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Text != transcript.Text || saved.FilePath != filePath {
+		t.Errorf("unexpected JSON sidecar %+v, %v", saved, err)
+	}
+}
+
+func TestTranscribeDiarizedWords(t *testing.T) {
+	form := make(map[string]string)
+	s := newTranscribeServer(t, form)
+	filePath := filepath.Join(t.TempDir(), "meeting.wav")
+	if err := os.WriteFile(filePath, wavHeader(16000, 0), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	transcript, err := s.Transcribe(context.Background(), filePath, TranscribeOptions{Diarize: true, NumSpeakers: 2, LanguageCode: "en"})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if form["diarize"] != "true" || form["num_speakers"] != "2" || form["language_code"] != "en" || form["timestamps_granularity"] != "word" {
+		t.Errorf("unexpected request form %v", form)
+	}
+	if want := "speaker_0: Hello there.\nspeaker_1: Hi!"; transcript.Text != want {
+		t.Errorf("expected speaker turns %q, got %q", want, transcript.Text)
+	}
+	if len(transcript.Words) != 3 || transcript.Words[2] != (TranscriptWord{Text: "Hi!", Start: 1.2, End: 1.5, SpeakerID: "speaker_1"}) {
+		t.Errorf("unexpected words %+v", transcript.Words)
+	}
+}
+
+func TestTranscribeValidation(t *testing.T) {
+	s := &Server{}
+	if _, err := s.Transcribe(context.Background(), filepath.Join(t.TempDir(), "missing.mp3"), TranscribeOptions{}); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	filePath := filepath.Join(t.TempDir(), "clip.mp3")
+	if err := os.WriteFile(filePath, []byte("ID3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Transcribe(context.Background(), filePath, TranscribeOptions{NumSpeakers: 40}); err == nil || !strings.Contains(err.Error(), "num_speakers") {
+		t.Errorf("expected num_speakers error, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package ximcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+	TransportSSE   = "sse"
+
+	DefaultHTTPAddress  = "localhost:8080"
+	httpShutdownTimeout = 5 * time.Second
+	authTokenLifetime   = time.Hour
+)
+
+// Run serves MCP requests over transport until the client disconnects or ctx
+// is cancelled, then closes the audio output.
+func (s *Server) Run(ctx context.Context, transport mcp.Transport) error {
+	runErr := s.mcpServer.Run(ctx, transport)
+	s.closeOutput()
+	return runErr
+}
+
+// ListenAndServe serves MCP over streamable HTTP or legacy SSE on address
+// until ctx is cancelled, so several clients can share one server. When
+// token is set, every request must carry it as a bearer token.
+func (s *Server) ListenAndServe(ctx context.Context, transport, address, token string) error {
+	handler, err := s.HTTPHandler(transport, token)
+	if err != nil {
+		return err
+	}
+	defer s.closeOutput()
+
+	if token == "" && !isLoopbackAddress(address) {
+		log.Printf("Warning: serving on %s without an auth token", address)
+	}
+
+	httpServer := &http.Server{Addr: address, Handler: handler}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("failed to serve %s: %w", transport, err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down %s server: %w", transport, err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// HTTPHandler returns an http.Handler serving the MCP server over the given
+// HTTP transport, optionally protected by a static bearer token.
+func (s *Server) HTTPHandler(transport, token string) (http.Handler, error) {
+	getServer := func(*http.Request) *mcp.Server { return s.mcpServer }
+
+	var handler http.Handler
+	switch strings.ToLower(transport) {
+	case TransportHTTP:
+		handler = mcp.NewStreamableHTTPHandler(getServer, nil)
+	case TransportSSE:
+		handler = mcp.NewSSEHandler(getServer, nil)
+	default:
+		return nil, fmt.Errorf("unknown transport %q (expected stdio, http, or sse)", transport)
+	}
+
+	if token == "" {
+		return handler, nil
+	}
+	return auth.RequireBearerToken(staticTokenVerifier(token), nil)(handler), nil
+}
+
+func staticTokenVerifier(token string) auth.TokenVerifier {
+	return func(ctx context.Context, presented string, req *http.Request) (*auth.TokenInfo, error) {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			return nil, auth.ErrInvalidToken
+		}
+		return &auth.TokenInfo{Expiration: time.Now().Add(authTokenLifetime)}, nil
+	}
+}
+
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) closeOutput() {
+	if s.output == nil {
+		return
+	}
+	if err := s.output.Close(); err != nil {
+		log.Printf("Error closing audio output: %v", err)
+	}
+}
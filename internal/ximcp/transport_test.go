@@ -0,0 +1,118 @@
+package ximcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bearerTransport adds an Authorization header to every request.
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newHTTPTestServer(t *testing.T, transport, token string) *httptest.Server {
+	t.Helper()
+
+	s := &Server{mcpServer: mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)}
+	s.setupTools()
+
+	handler, err := s.HTTPHandler(transport, token)
+	if err != nil {
+		t.Fatalf("HTTPHandler failed: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+	return httpServer
+}
+
+func TestHTTPHandlerRejectsUnknownTransport(t *testing.T) {
+	s := &Server{}
+	if _, err := s.HTTPHandler("websocket", ""); err == nil {
+		t.Error("expected error for unknown transport")
+	}
+}
+
+func TestHTTPHandlerRequiresBearerToken(t *testing.T) {
+	httpServer := newHTTPTestServer(t, TransportHTTP, "secret")
+
+	for _, header := range []string{"", "Bearer wrong"} {
+		req, err := http.NewRequest(http.MethodPost, httpServer.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("expected 401 with Authorization %q, got %d", header, resp.StatusCode)
+		}
+	}
+}
+
+func TestHTTPTransportsServeTools(t *testing.T) {
+	tests := []struct {
+		transport string
+		client    func(endpoint string, httpClient *http.Client) mcp.Transport
+	}{
+		{TransportHTTP, func(endpoint string, httpClient *http.Client) mcp.Transport {
+			return &mcp.StreamableClientTransport{Endpoint: endpoint, HTTPClient: httpClient}
+		}},
+		{TransportSSE, func(endpoint string, httpClient *http.Client) mcp.Transport {
+			return &mcp.SSEClientTransport{Endpoint: endpoint, HTTPClient: httpClient}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transport, func(t *testing.T) {
+			httpServer := newHTTPTestServer(t, tt.transport, "secret")
+			httpClient := &http.Client{Transport: bearerTransport{token: "secret"}}
+
+			client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+			session, err := client.Connect(context.Background(), tt.client(httpServer.URL, httpClient), nil)
+			if err != nil {
+				t.Fatalf("connect failed: %v", err)
+			}
+			defer session.Close()
+
+			tools, err := session.ListTools(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("ListTools failed: %v", err)
+			}
+			if len(tools.Tools) == 0 {
+				t.Error("expected tools over HTTP")
+			}
+		})
+	}
+}
+
+func TestIsLoopbackAddress(t *testing.T) {
+	tests := map[string]bool{
+		"localhost:8080": true,
+		"127.0.0.1:8080": true,
+		"[::1]:8080":     true,
+		"0.0.0.0:8080":   false,
+		":8080":          false,
+		"example.com:80": false,
+	}
+	for address, expected := range tests {
+		if got := isLoopbackAddress(address); got != expected {
+			t.Errorf("isLoopbackAddress(%q) = %t, expected %t", address, got, expected)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ximcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const DefaultVoiceCacheTTL = 5 * time.Minute
+
+// StaleVoicesError accompanies a cached voice list that could not be
+// refreshed.
+type StaleVoicesError struct {
+	FetchedAt time.Time
+	Err       error
+}
+
+func (e *StaleVoicesError) Error() string {
+	return fmt.Sprintf("voice list from %s may be out of date: %v", e.FetchedAt.Format(time.RFC3339), e.Err)
+}
+
+func (e *StaleVoicesError) Unwrap() error {
+	return e.Err
+}
+
+// GetVoices returns the cached voices and the current selection. The cache
+// is refreshed first when forceRefresh is set or nothing is cached yet, and
+// in the background once it is older than the cache TTL. If the API is
+// unreachable but voices are cached, they are returned together with a
+// *StaleVoicesError.
+func (s *Server) GetVoices(forceRefresh bool) ([]types.VoiceResponseModel, *types.VoiceResponseModel, error) {
+	s.voicesMutex.RLock()
+	fetchedAt := s.voicesFetchedAt
+	s.voicesMutex.RUnlock()
+
+	switch {
+	case forceRefresh || fetchedAt.IsZero():
+		if err := s.refreshVoices(); err != nil && fetchedAt.IsZero() {
+			return nil, nil, err
+		}
+	case time.Since(fetchedAt) > s.cacheTTL():
+		s.refreshVoicesInBackground()
+	}
+
+	s.voicesMutex.RLock()
+	defer s.voicesMutex.RUnlock()
+
+	if s.voicesRefreshErr != nil {
+		return s.voices, s.currentVoice, &StaleVoicesError{FetchedAt: s.voicesFetchedAt, Err: s.voicesRefreshErr}
+	}
+	return s.voices, s.currentVoice, nil
+}
+
+// refreshVoices fetches the voice list. The request is made without holding
+// voicesMutex so readers are served from the cache meanwhile.
+func (s *Server) refreshVoices() error {
+	voices, err := s.client.GetVoices(context.Background())
+
+	s.voicesMutex.Lock()
+	defer s.voicesMutex.Unlock()
+
+	if err != nil {
+		s.voicesRefreshErr = err
+		return fmt.Errorf("failed to get voices: %w", err)
+	}
+
+	s.voices = voices
+	s.voicesFetchedAt = time.Now()
+	s.voicesRefreshErr = nil
+	s.reconcileCurrentVoice()
+	return nil
+}
+
+func (s *Server) refreshVoicesInBackground() {
+	s.voicesMutex.Lock()
+	defer s.voicesMutex.Unlock()
+
+	if s.voicesRefreshing {
+		return
+	}
+	s.voicesRefreshing = true
+
+	go func() {
+		if err := s.refreshVoices(); err != nil {
+			log.Printf("Warning: serving cached voices: %v", err)
+		}
+
+		s.voicesMutex.Lock()
+		s.voicesRefreshing = false
+		s.voicesMutex.Unlock()
+	}()
+}
+
+// ensureVoices retries loading the voices when the fetch at startup failed.
+func (s *Server) ensureVoices() error {
+	s.voicesMutex.RLock()
+	failed := s.voicesFetchedAt.IsZero() && s.voicesRefreshErr != nil
+	s.voicesMutex.RUnlock()
+
+	if !failed {
+		return nil
+	}
+	return s.refreshVoices()
+}
+
+func (s *Server) cacheTTL() time.Duration {
+	if s.voiceCacheTTL > 0 {
+		return s.voiceCacheTTL
+	}
+	return DefaultVoiceCacheTTL
+}
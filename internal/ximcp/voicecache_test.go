@@ -0,0 +1,139 @@
+package ximcp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/taigrr/elevenlabs/client"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+type voicesStandIn struct {
+	requests atomic.Int32
+	failing  atomic.Bool
+}
+
+// newVoiceCacheServer returns a server whose voices come from a stand-in
+// for the ElevenLabs voices endpoint.
+func newVoiceCacheServer(t *testing.T, ttl time.Duration) (*Server, *voicesStandIn) {
+	t.Helper()
+
+	standIn := &voicesStandIn{}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		standIn.requests.Add(1)
+		if standIn.failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"voices": []types.VoiceResponseModel{
+			{VoiceID: "abc123", Name: "Alice"},
+			{VoiceID: "def456", Name: "Bob"},
+		}})
+	}))
+	t.Cleanup(httpServer.Close)
+
+	s := &Server{
+		client:        client.New("test-key").WithEndpoint(httpServer.URL),
+		voiceCacheTTL: ttl,
+	}
+	return s, standIn
+}
+
+func TestGetVoicesUsesCache(t *testing.T) {
+	s, standIn := newVoiceCacheServer(t, time.Hour)
+
+	for range 3 {
+		voices, current, err := s.GetVoices(false)
+		if err != nil {
+			t.Fatalf("GetVoices failed: %v", err)
+		}
+		if len(voices) != 2 || current == nil || current.VoiceID != "abc123" {
+			t.Fatalf("unexpected voices %v, current %v", voices, current)
+		}
+	}
+	if got := standIn.requests.Load(); got != 1 {
+		t.Errorf("expected 1 request, got %d", got)
+	}
+
+	if _, _, err := s.GetVoices(true); err != nil {
+		t.Fatalf("forced refresh failed: %v", err)
+	}
+	if got := standIn.requests.Load(); got != 2 {
+		t.Errorf("expected force_refresh to refetch, got %d requests", got)
+	}
+}
+
+func TestGetVoicesServesStaleCache(t *testing.T) {
+	s, standIn := newVoiceCacheServer(t, time.Hour)
+	if _, _, err := s.GetVoices(false); err != nil {
+		t.Fatalf("GetVoices failed: %v", err)
+	}
+
+	standIn.failing.Store(true)
+	voices, _, err := s.GetVoices(true)
+
+	var staleErr *StaleVoicesError
+	if !errors.As(err, &staleErr) {
+		t.Fatalf("expected StaleVoicesError, got %v", err)
+	}
+	if len(voices) != 2 {
+		t.Errorf("expected cached voices, got %v", voices)
+	}
+
+	standIn.failing.Store(false)
+	if _, _, err := s.GetVoices(true); err != nil {
+		t.Errorf("expected warning to clear after a successful refresh, got %v", err)
+	}
+}
+
+func TestGetVoicesWithoutCacheFails(t *testing.T) {
+	s, standIn := newVoiceCacheServer(t, time.Hour)
+	standIn.failing.Store(true)
+
+	if _, _, err := s.GetVoices(false); err == nil {
+		t.Fatal("expected error with nothing cached")
+	}
+}
+
+func TestGetVoicesRefreshesExpiredCacheInBackground(t *testing.T) {
+	s, standIn := newVoiceCacheServer(t, time.Millisecond)
+	if _, _, err := s.GetVoices(false); err != nil {
+		t.Fatalf("GetVoices failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, _, err := s.GetVoices(false); err != nil {
+		t.Fatalf("GetVoices failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for standIn.requests.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the expired cache to be refreshed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestInitialVoiceSelectedAfterFailedStartup(t *testing.T) {
+	s, standIn := newVoiceCacheServer(t, time.Hour)
+	standIn.failing.Store(true)
+
+	if err := s.initializeVoices("def456"); err == nil {
+		t.Fatal("expected initial fetch to fail")
+	}
+
+	standIn.failing.Store(false)
+	voice, err := s.speechVoice("")
+	if err != nil {
+		t.Fatalf("speechVoice failed: %v", err)
+	}
+	if voice.VoiceID != "def456" {
+		t.Errorf("expected the saved voice once voices load, got %s", voice.VoiceID)
+	}
+}
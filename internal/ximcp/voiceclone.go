@@ -0,0 +1,149 @@
+package ximcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const (
+	MaxVoiceSamples     = 25
+	MaxVoiceSampleBytes = 10 << 20
+)
+
+var voiceSampleExtensions = []string{".mp3", ".wav", ".m4a", ".flac", ".ogg", ".webm", ".aac"}
+
+// AddVoice creates an instant voice clone from local audio samples and
+// returns it once it appears in the refreshed voice list.
+func (s *Server) AddVoice(ctx context.Context, name, description string, labels map[string]string, samplePaths []string) (*types.VoiceResponseModel, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("voice name is required")
+	}
+	if err := validateVoiceSamples(samplePaths); err != nil {
+		return nil, err
+	}
+
+	files := make([]*os.File, 0, len(samplePaths))
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for _, samplePath := range samplePaths {
+		file, err := os.Open(samplePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open sample: %w", err)
+		}
+		files = append(files, file)
+	}
+
+	existing := make(map[string]bool)
+	s.voicesMutex.RLock()
+	for _, voice := range s.voices {
+		existing[voice.VoiceID] = true
+	}
+	s.voicesMutex.RUnlock()
+
+	labelList, err := encodeVoiceLabels(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.client.CreateVoice(ctx, name, description, labelList, files); err != nil {
+		return nil, fmt.Errorf("failed to create voice: %w", err)
+	}
+
+	if err := s.refreshVoices(); err != nil {
+		return nil, fmt.Errorf("voice created, but %w", err)
+	}
+
+	s.voicesMutex.RLock()
+	defer s.voicesMutex.RUnlock()
+
+	for i, voice := range s.voices {
+		if !existing[voice.VoiceID] && voice.Name == name {
+			created := s.voices[i]
+			return &created, nil
+		}
+	}
+	return nil, fmt.Errorf("voice %s was created but is not yet listed; try get_voices with force_refresh", name)
+}
+
+// DeleteVoice permanently removes a voice, given by ID or name, from the
+// account. Premade voices cannot be deleted.
+func (s *Server) DeleteVoice(ctx context.Context, voice string) (types.VoiceResponseModel, error) {
+	if err := s.ensureVoices(); err != nil {
+		return types.VoiceResponseModel{}, err
+	}
+
+	s.voicesMutex.RLock()
+	found, err := s.findVoice(voice)
+	var target types.VoiceResponseModel
+	if err == nil {
+		target = *found
+	}
+	s.voicesMutex.RUnlock()
+
+	if err != nil {
+		return target, err
+	}
+	if target.Category == "premade" {
+		return target, fmt.Errorf("%s is a premade voice and cannot be deleted", target.Name)
+	}
+
+	if err := s.client.DeleteVoice(ctx, target.VoiceID); err != nil {
+		return target, fmt.Errorf("failed to delete voice: %w", err)
+	}
+
+	if err := s.refreshVoices(); err != nil {
+		return target, fmt.Errorf("voice deleted, but %w", err)
+	}
+	return target, nil
+}
+
+func validateVoiceSamples(samplePaths []string) error {
+	if len(samplePaths) == 0 {
+		return fmt.Errorf("at least one audio sample is required")
+	}
+	if len(samplePaths) > MaxVoiceSamples {
+		return fmt.Errorf("at most %d audio samples are allowed, got %d", MaxVoiceSamples, len(samplePaths))
+	}
+
+	for _, samplePath := range samplePaths {
+		info, err := statAudioFile(samplePath)
+		if err != nil {
+			return fmt.Errorf("%s: %w", samplePath, err)
+		}
+
+		if !slices.Contains(voiceSampleExtensions, strings.ToLower(filepath.Ext(samplePath))) {
+			return fmt.Errorf("%s: unsupported sample format (expected %s)", samplePath, strings.Join(voiceSampleExtensions, ", "))
+		}
+
+		if info.Size() > MaxVoiceSampleBytes {
+			return fmt.Errorf("%s: sample is %d bytes, over the %d byte limit", samplePath, info.Size(), MaxVoiceSampleBytes)
+		}
+	}
+	return nil
+}
+
+// encodeVoiceLabels encodes labels as the JSON object the API expects. The
+// client joins the list it is given into a single form field, so the object
+// is passed as its only element.
+func encodeVoiceLabels(labels map[string]string) ([]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode voice labels: %w", err)
+	}
+	return []string{string(data)}, nil
+}
@@ -0,0 +1,158 @@
+package ximcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+// newVoiceLibraryServer returns a server backed by a stand-in voice library
+// that supports adding and deleting voices. The fields of the last add
+// request are recorded in form when it is not nil.
+func newVoiceLibraryServer(t *testing.T, form map[string][]string) *Server {
+	t.Helper()
+
+	var mutex sync.Mutex
+	voices := []types.VoiceResponseModel{
+		{VoiceID: "premade1", Name: "Rachel", Category: "premade"},
+		{VoiceID: "clone1", Name: "Old Clone", Category: "cloned"},
+	}
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/voices":
+			json.NewEncoder(w).Encode(map[string]any{"voices": voices})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/voices/add":
+			if err := r.ParseMultipartForm(1 << 20); err != nil || len(r.MultipartForm.File["files"]) == 0 {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			if form != nil {
+				for key, values := range r.MultipartForm.Value {
+					form[key] = values
+				}
+			}
+			voices = append(voices, types.VoiceResponseModel{VoiceID: "clone2", Name: r.FormValue("name"), Category: "cloned"})
+		case r.Method == http.MethodDelete:
+			voiceID := strings.TrimPrefix(r.URL.Path, "/v1/voices/")
+			for i, voice := range voices {
+				if voice.VoiceID == voiceID {
+					voices = append(voices[:i], voices[i+1:]...)
+					return
+				}
+			}
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(httpServer.Close)
+
+	s := &Server{client: client.New("test-key").WithEndpoint(httpServer.URL)}
+	if err := s.refreshVoices(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func writeSample(t *testing.T, name string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte("sample"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAddVoice(t *testing.T) {
+	form := make(map[string][]string)
+	s := newVoiceLibraryServer(t, form)
+
+	labels := map[string]string{"accent": "american", "use case": "narration, audiobooks"}
+	voice, err := s.AddVoice(context.Background(), "My Voice", "narration", labels, []string{writeSample(t, "sample.mp3")})
+	if err != nil {
+		t.Fatalf("AddVoice failed: %v", err)
+	}
+	if voice.VoiceID != "clone2" || voice.Name != "My Voice" {
+		t.Errorf("unexpected voice: %+v", voice)
+	}
+	if want := `{"accent":"american","use case":"narration, audiobooks"}`; len(form["labels"]) != 1 || form["labels"][0] != want {
+		t.Errorf("expected labels sent as the JSON object %s, got %q", want, form["labels"])
+	}
+	if s.findVoiceByID("clone2") == nil {
+		t.Error("expected the voice list to be refreshed")
+	}
+}
+
+func TestAddVoiceValidatesSamples(t *testing.T) {
+	s := newVoiceLibraryServer(t, nil)
+
+	tests := []struct {
+		name    string
+		samples []string
+	}{
+		{"no samples", nil},
+		{"missing file", []string{filepath.Join(t.TempDir(), "missing.mp3")}},
+		{"unsupported format", []string{writeSample(t, "notes.txt")}},
+		{"directory", []string{t.TempDir()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.AddVoice(context.Background(), "My Voice", "", nil, tt.samples); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestDeleteVoice(t *testing.T) {
+	s := newVoiceLibraryServer(t, nil)
+	s.currentVoice = s.findVoiceByID("clone1")
+
+	voice, err := s.DeleteVoice(context.Background(), "old clone")
+	if err != nil {
+		t.Fatalf("DeleteVoice failed: %v", err)
+	}
+	if voice.VoiceID != "clone1" {
+		t.Errorf("expected clone1 to be deleted, got %s", voice.VoiceID)
+	}
+	if s.findVoiceByID("clone1") != nil {
+		t.Error("expected the voice list to be refreshed")
+	}
+	if s.currentVoice == nil || s.currentVoice.VoiceID != "premade1" {
+		t.Errorf("expected selection to fall back to the first voice, got %+v", s.currentVoice)
+	}
+
+	if _, err := s.DeleteVoice(context.Background(), "Rachel"); err == nil {
+		t.Error("expected premade voices to be protected")
+	}
+}
+
+func TestDeleteVoiceRequiresConfirm(t *testing.T) {
+	s := newVoiceLibraryServer(t, nil)
+
+	result, _, err := s.deleteVoice(context.Background(), nil, DeleteVoiceArgs{Voice: "clone1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("expected an error result without confirm")
+	}
+	if s.findVoiceByID("clone1") == nil {
+		t.Error("voice should not be deleted without confirm")
+	}
+}
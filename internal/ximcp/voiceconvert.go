@@ -0,0 +1,103 @@
+package ximcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DefaultConversionModelID = "eleven_multilingual_sts_v2"
+	// ClipKindVoiceConversion marks clips re-rendered from a recording by
+	// speech-to-speech in their metadata.
+	ClipKindVoiceConversion = "voice_conversion"
+)
+
+// ConvertVoice re-renders the speech in a local recording, or a prior clip
+// given as an xi://audio/ URI, in another voice. Voice, format, and settings
+// resolve as for text-to-speech, but the model defaults to
+// DefaultConversionModelID.
+func (s *Server) ConvertVoice(ctx context.Context, source string, speechOptions SpeechOptions) (*GeneratedAudio, error) {
+	sourcePath, err := s.resolveSourceAudio(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.resolveSpeechTarget(ctx, speechOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	modelID := DefaultConversionModelID
+	if override := strings.TrimSpace(speechOptions.ModelID); override != "" {
+		modelID = override
+	}
+
+	stream, err := s.client.SpeechToSpeech(ctx, sourcePath, target.voice.VoiceID, modelID, target.format.Name, target.options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert voice: %w", err)
+	}
+	defer stream.Close()
+
+	var audioData bytes.Buffer
+	if _, err := io.Copy(newSampleConverter(&audioData, target.format), stream); err != nil {
+		return nil, fmt.Errorf("failed to read converted audio: %w", err)
+	}
+
+	// A saved clip, recognized by its metadata, keeps its text and records
+	// the voice it was made with.
+	sourceDirectory, sourceName := filepath.Split(sourcePath)
+	sourceMetadata := s.getAudioMetadata(sourceDirectory, sourceName)
+	text := fmt.Sprintf("Voice conversion of %s", sourceName)
+	if _, err := os.Stat(trimAudioExtension(sourcePath) + MetadataFileSuffix); err == nil {
+		if content, err := os.ReadFile(trimAudioExtension(sourcePath) + ".txt"); err == nil {
+			text = string(content)
+		}
+	}
+
+	filePath, err := s.saveAudioFiles(ctx, text, audioData.Bytes(), AudioMetadata{
+		Kind:            ClipKindVoiceConversion,
+		VoiceID:         target.voice.VoiceID,
+		VoiceName:       target.voice.Name,
+		ModelID:         modelID,
+		OutputFormat:    target.format.Name,
+		Settings:        &target.options,
+		SourceFile:      sourcePath,
+		SourceVoiceID:   sourceMetadata.VoiceID,
+		SourceVoiceName: sourceMetadata.VoiceName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GeneratedAudio{
+		FilePath:     filePath,
+		VoiceID:      target.voice.VoiceID,
+		VoiceName:    target.voice.Name,
+		ModelID:      modelID,
+		OutputFormat: target.format.Name,
+		Settings:     target.options,
+		SourceFile:   sourcePath,
+		Duration:     s.clipDuration(filePath),
+	}, nil
+}
+
+// resolveSourceAudio returns the path of source, which is either a file path
+// or the xi://audio/ URI of a clip in the audio directory for ctx.
+func (s *Server) resolveSourceAudio(ctx context.Context, source string) (string, error) {
+	if name, ok := strings.CutPrefix(source, AudioResourcePrefix); ok {
+		if name == "" || name != filepath.Base(name) || !isAudioFile(name) {
+			return "", fmt.Errorf("%s is not an audio clip", source)
+		}
+		source = filepath.Join(s.audioDirectory(ctx), name)
+	}
+
+	if err := validateAudioFilePath(source); err != nil {
+		return "", err
+	}
+	return filepath.Abs(source)
+}
@@ -0,0 +1,133 @@
+package ximcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+func newConversionServer(t *testing.T, form map[string]string) *Server {
+	t.Helper()
+
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		voiceID, ok := strings.CutPrefix(r.URL.Path, "/v1/speech-to-speech/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		form["voice_id"] = voiceID
+		form["model_id"] = r.FormValue("model_id")
+		form["output_format"] = r.URL.Query().Get("output_format")
+		w.Write([]byte("ID3 converted"))
+	}))
+	t.Cleanup(standIn.Close)
+
+	s := &Server{
+		client:    client.New("test-key").WithEndpoint(standIn.URL),
+		audioRoot: t.TempDir(),
+		voices: []types.VoiceResponseModel{
+			{VoiceID: "abc123", Name: "Alice"},
+			{VoiceID: "def456", Name: "Bob"},
+		},
+	}
+	s.currentVoice = &s.voices[0]
+	return s
+}
+
+func TestConvertVoiceFromClip(t *testing.T) {
+	form := make(map[string]string)
+	s := newConversionServer(t, form)
+	ctx := context.Background()
+
+	clipPath, err := s.saveAudioFiles(ctx, "Hello from Alice.", []byte("ID3 original"), AudioMetadata{VoiceID: "abc123", VoiceName: "Alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	audio, err := s.ConvertVoice(ctx, AudioResourcePrefix+filepath.Base(clipPath), SpeechOptions{Voice: "bob"})
+	if err != nil {
+		t.Fatalf("ConvertVoice failed: %v", err)
+	}
+	if form["voice_id"] != "def456" || form["model_id"] != DefaultConversionModelID || form["output_format"] != DefaultOutputFormat {
+		t.Errorf("unexpected request %v", form)
+	}
+	if audio.SourceFile != clipPath || audio.VoiceName != "Bob" {
+		t.Errorf("unexpected result %+v", audio)
+	}
+
+	history, err := s.GetAudioHistory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var converted *AudioFile
+	for i := range history {
+		if history[i].FilePath == audio.FilePath {
+			converted = &history[i]
+		}
+	}
+	if converted == nil {
+		t.Fatalf("converted clip missing from history %+v", history)
+	}
+	if converted.Kind != ClipKindVoiceConversion || converted.SourceFile != clipPath || converted.SourceVoiceName != "Alice" ||
+		converted.VoiceName != "Bob" || converted.Summary != "Hello from Alice." {
+		t.Errorf("unexpected history entry %+v", converted)
+	}
+
+	listing := s.formatHistoryList([]AudioFile{*converted})
+	if want := "converted from " + clipPath + " (Alice) to Bob"; !strings.Contains(listing, want) {
+		t.Errorf("expected %q in history listing:\n%s", want, listing)
+	}
+}
+
+func TestConvertVoiceFromRecording(t *testing.T) {
+	form := make(map[string]string)
+	s := newConversionServer(t, form)
+	recording := filepath.Join(t.TempDir(), "memo.mp3")
+	if err := os.WriteFile(recording, []byte("recording"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Notes next to a recording are not a clip's text.
+	if err := os.WriteFile(strings.TrimSuffix(recording, ".mp3")+".txt", []byte("Call the plumber"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	audio, err := s.ConvertVoice(context.Background(), recording, SpeechOptions{ModelID: "eleven_english_sts_v2", OutputFormat: "pcm_16000"})
+	if err != nil {
+		t.Fatalf("ConvertVoice failed: %v", err)
+	}
+	if form["voice_id"] != "abc123" || form["model_id"] != "eleven_english_sts_v2" || form["output_format"] != "pcm_16000" {
+		t.Errorf("unexpected request %v", form)
+	}
+	if !strings.HasSuffix(audio.FilePath, ".wav") {
+		t.Errorf("expected a WAV clip, got %s", audio.FilePath)
+	}
+
+	metadata := s.getAudioMetadata(filepath.Split(audio.FilePath))
+	if metadata.SourceFile != recording || metadata.SourceVoiceName != "" || metadata.VoiceName != "Alice" {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+	if summary := s.getAudioSummary(filepath.Split(audio.FilePath)); summary != "Voice conversion of memo.mp3" {
+		t.Errorf("unexpected summary %q", summary)
+	}
+}
+
+func TestConvertVoiceRejectsBadSources(t *testing.T) {
+	s := newConversionServer(t, make(map[string]string))
+
+	for _, source := range []string{"", AudioResourcePrefix + "../secret.mp3", AudioResourcePrefix + "notes.txt", AudioResourcePrefix + "missing.mp3"} {
+		if _, err := s.ConvertVoice(context.Background(), source, SpeechOptions{}); err == nil {
+			t.Errorf("expected error for source %q", source)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ximcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const (
+	DefaultVoicePageSize = 25
+	MaxVoicePageSize     = 100
+)
+
+// VoiceFilter narrows the voices listed by get_voices. Empty fields match
+// every voice.
+type VoiceFilter struct {
+	Category string `json:"category,omitempty" jsonschema:"Only voices in this category, such as premade, cloned, generated, or professional"`
+	Accent   string `json:"accent,omitempty" jsonschema:"Only voices with this accent label, such as american or british"`
+	Gender   string `json:"gender,omitempty" jsonschema:"Only voices with this gender label"`
+	Age      string `json:"age,omitempty" jsonschema:"Only voices with this age label, such as young or middle aged"`
+	UseCase  string `json:"use_case,omitempty" jsonschema:"Only voices with this use case label, such as narration or conversational"`
+	Search   string `json:"search,omitempty" jsonschema:"Words that must all appear in the voice name or description"`
+}
+
+// filterVoices returns the voices matching filter, in their original order.
+func filterVoices(voices []types.VoiceResponseModel, filter VoiceFilter) []types.VoiceResponseModel {
+	matches := make([]types.VoiceResponseModel, 0, len(voices))
+	for _, voice := range voices {
+		if filter.matches(voice) {
+			matches = append(matches, voice)
+		}
+	}
+	return matches
+}
+
+func (f VoiceFilter) matches(voice types.VoiceResponseModel) bool {
+	if f.Category != "" && !strings.EqualFold(strings.TrimSpace(f.Category), voice.Category) {
+		return false
+	}
+
+	labels := map[string]string{
+		"accent":   f.Accent,
+		"gender":   f.Gender,
+		"age":      f.Age,
+		"use_case": f.UseCase,
+	}
+	for key, want := range labels {
+		if want != "" && !containsWords(voiceLabel(voice, key), want) {
+			return false
+		}
+	}
+
+	if f.Search != "" {
+		text := normalizeVoiceName(voice.Name + " " + voice.Description + " " + voiceLabel(voice, "description"))
+		for _, term := range strings.Fields(normalizeVoiceName(f.Search)) {
+			if !strings.Contains(text, term) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// voiceLabel returns a voice label, accepting both "use_case" and
+// "use case" style keys.
+func voiceLabel(voice types.VoiceResponseModel, key string) string {
+	for labelKey, value := range voice.Labels {
+		if strings.EqualFold(strings.ReplaceAll(labelKey, " ", "_"), key) {
+			return value
+		}
+	}
+	return ""
+}
+
+// containsWords reports whether want appears in value as whole words, so
+// "male" does not match "female" but "middle" matches "middle aged".
+func containsWords(value, want string) bool {
+	value, want = normalizeVoiceName(value), normalizeVoiceName(want)
+	return want != "" && strings.Contains(" "+value+" ", " "+want+" ")
+}
+
+// pageBounds returns the slice bounds for one page of total items.
+func pageBounds(total, offset, limit int) (int, int, error) {
+	if offset < 0 {
+		return 0, 0, fmt.Errorf("offset must not be negative, got %d", offset)
+	}
+	if limit < 0 {
+		return 0, 0, fmt.Errorf("limit must not be negative, got %d", limit)
+	}
+	if limit == 0 {
+		limit = DefaultVoicePageSize
+	}
+	limit = min(limit, MaxVoicePageSize)
+
+	start := min(offset, total)
+	return start, min(start+limit, total), nil
+}
+
+func formatVoiceLabels(voice types.VoiceResponseModel) string {
+	var labels []string
+	for _, key := range []string{"accent", "gender", "age", "use_case"} {
+		if value := voiceLabel(voice, key); value != "" {
+			labels = append(labels, fmt.Sprintf("%s: %s", strings.ReplaceAll(key, "_", " "), value))
+		}
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(labels, ", ") + "]"
+}
@@ -0,0 +1,96 @@
+package ximcp
+
+import (
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+func filterTestVoices() []types.VoiceResponseModel {
+	return []types.VoiceResponseModel{
+		{
+			VoiceID: "rachel", Name: "Rachel", Category: "premade",
+			Labels:      map[string]string{"accent": "american", "gender": "female", "age": "young", "use case": "narration"},
+			Description: "Calm and clear",
+		},
+		{
+			VoiceID: "george", Name: "George", Category: "premade",
+			Labels: map[string]string{"accent": "british", "gender": "male", "age": "middle aged", "use_case": "narration"},
+		},
+		{
+			VoiceID: "clone", Name: "My Clone", Category: "cloned",
+			Labels: map[string]string{"accent": "american", "gender": "male", "description": "warm podcast host"},
+		},
+	}
+}
+
+func voiceIDs(voices []types.VoiceResponseModel) []string {
+	ids := make([]string, len(voices))
+	for i, voice := range voices {
+		ids[i] = voice.VoiceID
+	}
+	return ids
+}
+
+func TestFilterVoices(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   VoiceFilter
+		expected []string
+	}{
+		{"no filter", VoiceFilter{}, []string{"rachel", "george", "clone"}},
+		{"category", VoiceFilter{Category: "Cloned"}, []string{"clone"}},
+		{"gender does not match substrings", VoiceFilter{Gender: "male"}, []string{"george", "clone"}},
+		{"partial multi-word label", VoiceFilter{Age: "middle"}, []string{"george"}},
+		{"use case with spaced key", VoiceFilter{UseCase: "narration"}, []string{"rachel", "george"}},
+		{"combined", VoiceFilter{Accent: "american", Gender: "male"}, []string{"clone"}},
+		{"search name", VoiceFilter{Search: "geo"}, []string{"george"}},
+		{"search description", VoiceFilter{Search: "calm"}, []string{"rachel"}},
+		{"search description label", VoiceFilter{Search: "podcast warm"}, []string{"clone"}},
+		{"no matches", VoiceFilter{Accent: "australian"}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := voiceIDs(filterVoices(filterTestVoices(), tt.filter))
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		name                 string
+		total, offset, limit int
+		start, end           int
+	}{
+		{"default limit", 40, 0, 0, 0, DefaultVoicePageSize},
+		{"second page", 40, 25, 10, 25, 35},
+		{"last page", 40, 35, 10, 35, 40},
+		{"past the end", 40, 50, 10, 40, 40},
+		{"limit capped", 500, 0, 1000, 0, MaxVoicePageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := pageBounds(tt.total, tt.offset, tt.limit)
+			if err != nil {
+				t.Fatalf("pageBounds failed: %v", err)
+			}
+			if start != tt.start || end != tt.end {
+				t.Errorf("expected [%d:%d], got [%d:%d]", tt.start, tt.end, start, end)
+			}
+		})
+	}
+
+	if _, _, err := pageBounds(10, -1, 0); err == nil {
+		t.Error("expected error for negative offset")
+	}
+}
@@ -0,0 +1,185 @@
+package ximcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+const (
+	maxVoiceSuggestions  = 10
+	minSubsequenceLength = 3
+)
+
+// Match quality, worst first; higher is better.
+const (
+	matchNone = iota
+	matchTypo
+	matchSubsequence
+	matchSubstring
+	matchWordPrefix
+)
+
+// AmbiguousVoiceError lists the voices matching a query, best match first.
+type AmbiguousVoiceError struct {
+	Query   string
+	Matches []types.VoiceResponseModel
+}
+
+func (e *AmbiguousVoiceError) Error() string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "multiple voices match '%s':", e.Query)
+	for i, voice := range e.Matches {
+		if i == maxVoiceSuggestions {
+			fmt.Fprintf(&message, "\n  ... and %d more", len(e.Matches)-maxVoiceSuggestions)
+			break
+		}
+		fmt.Fprintf(&message, "\n  %d. %s (%s)", i+1, voice.Name, voice.VoiceID)
+	}
+	message.WriteString("\nUse the voice ID or the full name to pick one")
+	return message.String()
+}
+
+// findVoice resolves query to a voice by exact ID, then case-insensitive
+// name, then fuzzy name match. The caller must hold voicesMutex.
+func (s *Server) findVoice(query string) (*types.VoiceResponseModel, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("voice is required")
+	}
+
+	if voice := s.findVoiceByID(query); voice != nil {
+		return voice, nil
+	}
+
+	var exact []int
+	for i, voice := range s.voices {
+		if strings.EqualFold(strings.TrimSpace(voice.Name), query) {
+			exact = append(exact, i)
+		}
+	}
+	if len(exact) == 1 {
+		return &s.voices[exact[0]], nil
+	}
+	if len(exact) > 1 {
+		return nil, s.ambiguousVoice(query, exact)
+	}
+
+	type candidate struct {
+		index int
+		score int
+	}
+	var candidates []candidate
+	normalizedQuery := normalizeVoiceName(query)
+	for i, voice := range s.voices {
+		if score := voiceMatchScore(normalizeVoiceName(voice.Name), normalizedQuery); score != matchNone {
+			candidates = append(candidates, candidate{index: i, score: score})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no voice matches '%s'", query)
+	}
+
+	sort.SliceStable(candidates, func(first, second int) bool {
+		firstCandidate, secondCandidate := candidates[first], candidates[second]
+		if firstCandidate.score != secondCandidate.score {
+			return firstCandidate.score > secondCandidate.score
+		}
+		firstName := normalizeVoiceName(s.voices[firstCandidate.index].Name)
+		secondName := normalizeVoiceName(s.voices[secondCandidate.index].Name)
+		if firstPrefix, secondPrefix := strings.HasPrefix(firstName, normalizedQuery), strings.HasPrefix(secondName, normalizedQuery); firstPrefix != secondPrefix {
+			return firstPrefix
+		}
+		return len(firstName) < len(secondName)
+	})
+
+	// A single best match wins over weaker ones, e.g. "rach" picks Rachel
+	// even though it is also a subsequence of other names.
+	if len(candidates) == 1 || candidates[0].score > candidates[1].score {
+		return &s.voices[candidates[0].index], nil
+	}
+
+	ranked := make([]int, len(candidates))
+	for i, candidate := range candidates {
+		ranked[i] = candidate.index
+	}
+	return nil, s.ambiguousVoice(query, ranked)
+}
+
+func (s *Server) ambiguousVoice(query string, indexes []int) error {
+	matches := make([]types.VoiceResponseModel, len(indexes))
+	for i, index := range indexes {
+		matches[i] = s.voices[index]
+	}
+	return &AmbiguousVoiceError{Query: query, Matches: matches}
+}
+
+// normalizeVoiceName lowercases name and collapses punctuation to spaces.
+func normalizeVoiceName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+func voiceMatchScore(name, query string) int {
+	if query == "" || name == "" {
+		return matchNone
+	}
+
+	switch {
+	case strings.Contains(" "+name, " "+query):
+		return matchWordPrefix
+	case strings.Contains(name, query):
+		return matchSubstring
+	case len(query) >= minSubsequenceLength && isSubsequence(strings.ReplaceAll(query, " ", ""), name):
+		return matchSubsequence
+	}
+
+	// Allow roughly one typo per four characters against any word.
+	maxDistance := max(1, len(query)/4)
+	for _, word := range append(strings.Fields(name), name) {
+		if levenshtein(word, query) <= maxDistance {
+			return matchTypo
+		}
+	}
+	return matchNone
+}
+
+func isSubsequence(query, name string) bool {
+	remaining := []rune(query)
+	for _, r := range name {
+		if len(remaining) == 0 {
+			break
+		}
+		if r == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
+
+func levenshtein(first, second string) int {
+	a, b := []rune(first), []rune(second)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
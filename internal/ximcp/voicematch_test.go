@@ -0,0 +1,116 @@
+package ximcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+func newVoiceMatchServer() *Server {
+	return &Server{
+		voices: []types.VoiceResponseModel{
+			{VoiceID: "21m00Tcm4TlvDq8ikWAM", Name: "Rachel"},
+			{VoiceID: "AZnzlk1XvdvUeBnXmlld", Name: "Domi"},
+			{VoiceID: "EXAVITQu4vr4xnSDxMaL", Name: "Sarah"},
+			{VoiceID: "pNInz6obpgDQGcFmaJgB", Name: "Adam"},
+			{VoiceID: "custom1", Name: "Adam - Narrator"},
+			{VoiceID: "custom2", Name: "Narrator Deep"},
+		},
+	}
+}
+
+func TestFindVoice(t *testing.T) {
+	s := newVoiceMatchServer()
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"exact id", "EXAVITQu4vr4xnSDxMaL", "EXAVITQu4vr4xnSDxMaL"},
+		{"case-insensitive name", "rachel", "21m00Tcm4TlvDq8ikWAM"},
+		{"exact name beats prefix", "ADAM", "pNInz6obpgDQGcFmaJgB"},
+		{"prefix", "rach", "21m00Tcm4TlvDq8ikWAM"},
+		{"substring", "omi", "AZnzlk1XvdvUeBnXmlld"},
+		{"typo", "Rachael", "21m00Tcm4TlvDq8ikWAM"},
+		{"surrounding whitespace", "  sarah ", "EXAVITQu4vr4xnSDxMaL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voice, err := s.findVoice(tt.query)
+			if err != nil {
+				t.Fatalf("findVoice(%q) failed: %v", tt.query, err)
+			}
+			if voice.VoiceID != tt.expected {
+				t.Errorf("findVoice(%q) = %s, expected %s", tt.query, voice.VoiceID, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFindVoiceAmbiguous(t *testing.T) {
+	s := newVoiceMatchServer()
+
+	_, err := s.findVoice("narrator")
+	var ambiguous *AmbiguousVoiceError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected AmbiguousVoiceError, got %v", err)
+	}
+
+	if len(ambiguous.Matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(ambiguous.Matches))
+	}
+	// "Narrator Deep" starts with the query, so it ranks first.
+	if ambiguous.Matches[0].VoiceID != "custom2" || ambiguous.Matches[1].VoiceID != "custom1" {
+		t.Errorf("unexpected ranking: %+v", ambiguous.Matches)
+	}
+	if !strings.Contains(err.Error(), "1. Narrator Deep (custom2)") {
+		t.Errorf("expected ranked list in error, got %q", err.Error())
+	}
+}
+
+func TestFindVoiceNoMatch(t *testing.T) {
+	s := newVoiceMatchServer()
+
+	for _, query := range []string{"zzzz", ""} {
+		if _, err := s.findVoice(query); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+}
+
+func TestSetVoiceByName(t *testing.T) {
+	s := newVoiceMatchServer()
+
+	voice, err := s.SetVoice("sarah")
+	if err != nil {
+		t.Fatalf("SetVoice failed: %v", err)
+	}
+	if voice.VoiceID != "EXAVITQu4vr4xnSDxMaL" || s.currentVoice.VoiceID != "EXAVITQu4vr4xnSDxMaL" {
+		t.Errorf("expected Sarah to be selected, got %+v", s.currentVoice)
+	}
+}
+
+func TestSpeechVoiceOverride(t *testing.T) {
+	s := newVoiceMatchServer()
+	s.currentVoice = &s.voices[0]
+
+	job, err := s.prepareSpeech(context.Background(), "hello", SpeechOptions{Voice: "domi"})
+	if err != nil {
+		t.Fatalf("prepareSpeech failed: %v", err)
+	}
+	if job.voice.VoiceID != "AZnzlk1XvdvUeBnXmlld" {
+		t.Errorf("expected Domi for this call, got %s", job.voice.Name)
+	}
+	if s.currentVoice.VoiceID != "21m00Tcm4TlvDq8ikWAM" {
+		t.Errorf("override should not change the current voice, got %s", s.currentVoice.Name)
+	}
+
+	if _, err := s.prepareSpeech(context.Background(), "hello", SpeechOptions{Voice: "zzzz"}); err == nil {
+		t.Error("expected error for unknown voice")
+	}
+}
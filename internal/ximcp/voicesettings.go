@@ -0,0 +1,105 @@
+package ximcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+// cachedVoiceSettings holds a voice's saved settings as last read from the
+// API.
+type cachedVoiceSettings struct {
+	options   types.SynthesisOptions
+	fetchedAt time.Time
+}
+
+// GetVoiceSettings returns the settings saved on the voice, given by ID or
+// name, or on the current voice when voice is empty.
+func (s *Server) GetVoiceSettings(ctx context.Context, voice string) (types.VoiceResponseModel, types.SynthesisOptions, error) {
+	target, err := s.speechVoice(voice)
+	if err != nil {
+		return target, types.SynthesisOptions{}, err
+	}
+
+	options, err := s.fetchVoiceSettings(ctx, target.VoiceID)
+	return target, options, err
+}
+
+// UpdateVoiceSettings changes the fields set in settings on the voice's
+// saved settings and returns the result.
+func (s *Server) UpdateVoiceSettings(ctx context.Context, voice string, settings VoiceSettings) (types.VoiceResponseModel, types.SynthesisOptions, error) {
+	if settings == (VoiceSettings{}) {
+		return types.VoiceResponseModel{}, types.SynthesisOptions{}, fmt.Errorf("no settings given to update")
+	}
+	if _, err := settings.apply(defaultSynthesisOptions()); err != nil {
+		return types.VoiceResponseModel{}, types.SynthesisOptions{}, err
+	}
+
+	target, saved, err := s.GetVoiceSettings(ctx, voice)
+	if err != nil {
+		return target, saved, err
+	}
+
+	options, err := settings.apply(saved)
+	if err != nil {
+		return target, saved, err
+	}
+
+	if err := s.client.EditVoiceSettings(ctx, target.VoiceID, options); err != nil {
+		return target, saved, fmt.Errorf("failed to update voice settings: %w", err)
+	}
+
+	s.cacheVoiceSettings(target.VoiceID, options)
+	return target, options, nil
+}
+
+// savedVoiceSettings returns the voice's saved settings for generation,
+// served from the cache while it is fresh. If they cannot be fetched, the
+// built-in defaults are used.
+func (s *Server) savedVoiceSettings(ctx context.Context, voiceID string) types.SynthesisOptions {
+	s.voicesMutex.RLock()
+	cached, ok := s.voiceSettingsCache[voiceID]
+	s.voicesMutex.RUnlock()
+	if ok && time.Since(cached.fetchedAt) <= s.cacheTTL() {
+		return cached.options
+	}
+
+	options, err := s.fetchVoiceSettings(ctx, voiceID)
+	if err != nil {
+		if ok {
+			log.Printf("Warning: using cached settings for voice %s: %v", voiceID, err)
+			return cached.options
+		}
+		log.Printf("Warning: using default settings for voice %s: %v", voiceID, err)
+		return defaultSynthesisOptions()
+	}
+	return options
+}
+
+func (s *Server) fetchVoiceSettings(ctx context.Context, voiceID string) (types.SynthesisOptions, error) {
+	options, err := s.client.GetVoiceSettings(ctx, voiceID)
+	if err != nil {
+		return options, fmt.Errorf("failed to get voice settings: %w", err)
+	}
+
+	// Older voices have no saved speed.
+	if options.Speed == 0 {
+		options.Speed = DefaultSpeed
+	}
+
+	s.cacheVoiceSettings(voiceID, options)
+	return options, nil
+}
+
+func (s *Server) cacheVoiceSettings(voiceID string, options types.SynthesisOptions) {
+	s.voicesMutex.Lock()
+	defer s.voicesMutex.Unlock()
+
+	if s.voiceSettingsCache == nil {
+		s.voiceSettingsCache = make(map[string]cachedVoiceSettings)
+	}
+	s.voiceSettingsCache[voiceID] = cachedVoiceSettings{options: options, fetchedAt: time.Now()}
+}
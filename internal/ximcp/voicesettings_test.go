@@ -0,0 +1,131 @@
+package ximcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/taigrr/elevenlabs/client"
+	"github.com/taigrr/elevenlabs/client/types"
+)
+
+// newVoiceSettingsServer returns a server backed by a stand-in that stores
+// per-voice settings, and a counter of settings reads.
+func newVoiceSettingsServer(t *testing.T) (*Server, *atomic.Int32) {
+	t.Helper()
+
+	var mutex sync.Mutex
+	var reads atomic.Int32
+	saved := map[string]types.SynthesisOptions{
+		"abc123": {Stability: 0.8, SimilarityBoost: 0.9, Style: 0.1},
+	}
+
+	standIn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		path := strings.TrimPrefix(r.URL.Path, "/v1/voices/")
+		switch voiceID, action, _ := strings.Cut(path, "/"); {
+		case r.Method == http.MethodGet && action == "settings":
+			reads.Add(1)
+			json.NewEncoder(w).Encode(saved[voiceID])
+		case r.Method == http.MethodPost && action == "settings/edit":
+			var options types.SynthesisOptions
+			if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			saved[voiceID] = options
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(standIn.Close)
+
+	s := &Server{
+		client: client.New("test-key").WithEndpoint(standIn.URL),
+		voices: []types.VoiceResponseModel{{VoiceID: "abc123", Name: "Alice"}},
+	}
+	s.currentVoice = &s.voices[0]
+	return s, &reads
+}
+
+func TestPrepareSpeechUsesSavedVoiceSettings(t *testing.T) {
+	s, reads := newVoiceSettingsServer(t)
+
+	job, err := s.prepareSpeech(context.Background(), "hello", SpeechOptions{})
+	if err != nil {
+		t.Fatalf("prepareSpeech failed: %v", err)
+	}
+	if job.options.Stability != 0.8 || job.options.SimilarityBoost != 0.9 || job.options.Speed != DefaultSpeed {
+		t.Errorf("expected the voice's saved settings, got %+v", job.options)
+	}
+
+	style := 0.5
+	job, err = s.prepareSpeech(context.Background(), "hello", SpeechOptions{VoiceSettings: VoiceSettings{Style: &style}})
+	if err != nil {
+		t.Fatalf("prepareSpeech failed: %v", err)
+	}
+	if job.options.Stability != 0.8 || job.options.Style != 0.5 {
+		t.Errorf("expected per-call override on top of saved settings, got %+v", job.options)
+	}
+
+	if got := reads.Load(); got != 1 {
+		t.Errorf("expected saved settings to be cached, got %d reads", got)
+	}
+}
+
+func TestUpdateVoiceSettings(t *testing.T) {
+	s, _ := newVoiceSettingsServer(t)
+
+	speed := 1.1
+	voice, options, err := s.UpdateVoiceSettings(context.Background(), "alice", VoiceSettings{Speed: &speed})
+	if err != nil {
+		t.Fatalf("UpdateVoiceSettings failed: %v", err)
+	}
+	if voice.VoiceID != "abc123" || options.Speed != 1.1 || options.Stability != 0.8 {
+		t.Errorf("expected only speed to change, got %+v", options)
+	}
+
+	_, saved, err := s.GetVoiceSettings(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetVoiceSettings failed: %v", err)
+	}
+	if saved != options {
+		t.Errorf("expected saved settings %+v, got %+v", options, saved)
+	}
+
+	job, err := s.prepareSpeech(context.Background(), "hello", SpeechOptions{})
+	if err != nil {
+		t.Fatalf("prepareSpeech failed: %v", err)
+	}
+	if job.options.Speed != 1.1 {
+		t.Errorf("expected generation to use the updated settings, got %+v", job.options)
+	}
+}
+
+func TestUpdateVoiceSettingsValidates(t *testing.T) {
+	s, _ := newVoiceSettingsServer(t)
+
+	if _, _, err := s.UpdateVoiceSettings(context.Background(), "", VoiceSettings{}); err == nil {
+		t.Error("expected error when no settings are given")
+	}
+
+	stability := 2.0
+	if _, _, err := s.UpdateVoiceSettings(context.Background(), "", VoiceSettings{Stability: &stability}); err == nil {
+		t.Error("expected error for out of range stability")
+	}
+}
+
+func TestSavedVoiceSettingsFallsBackToDefaults(t *testing.T) {
+	s := &Server{client: client.New("test-key").WithEndpoint("http://127.0.0.1:0")}
+
+	if options := s.savedVoiceSettings(context.Background(), "abc123"); options != defaultSynthesisOptions() {
+		t.Errorf("expected built-in defaults, got %+v", options)
+	}
+}
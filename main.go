@@ -2,8 +2,15 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"runtime/debug"
+	"strconv"
+	"strings"
+	"syscall"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/taigrr/elevenlabs-mcp/internal/ximcp"
@@ -23,15 +30,92 @@ func init() {
 	}
 }
 
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envBoolOrDefault(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// configPathFromArgs finds the -config flag before the other flags are
+// defined, since the file supplies their defaults.
+func configPathFromArgs(args []string) string {
+	for index, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if index+1 < len(args) {
+			return args[index+1]
+		}
+	}
+	return os.Getenv("XI_CONFIG")
+}
+
 func main() {
+	config := ximcp.DefaultConfig()
+	configPath, err := ximcp.LoadConfigFile(configPathFromArgs(os.Args[1:]), &config)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	flag.String("config", configPath, fmt.Sprintf("JSON config file (env XI_CONFIG); searched for in %s", strings.Join(ximcp.ConfigSearchPaths(), ", ")))
+	flag.StringVar(&config.VoiceID, "voice", envOrDefault("XI_VOICE_ID", config.VoiceID), "voice ID selected at startup (env XI_VOICE_ID)")
+	flag.StringVar(&config.ModelID, "model", envOrDefault("XI_MODEL_ID", config.ModelID), fmt.Sprintf("default text-to-speech model ID; when unset, the remembered model or %s (env XI_MODEL_ID)", ximcp.DefaultModelID))
+	flag.DurationVar(&config.VoiceCacheTTL, "voice-cache-ttl", config.VoiceCacheTTL, "how long the voice list is cached before it is refreshed")
+	flag.StringVar(&config.StateFile, "state-file", envOrDefault("XI_STATE_FILE", config.StateFile), "file remembering the voice, model, and settings selected at runtime; empty disables it (env XI_STATE_FILE)")
+	flag.StringVar(&config.AudioDirectory, "audio-dir", envOrDefault("XI_AUDIO_DIR", config.AudioDirectory), "directory for generated audio; clients reporting a project root get a subdirectory (env XI_AUDIO_DIR)")
+	flag.StringVar(&config.OutputFormat, "output-format", envOrDefault("XI_OUTPUT_FORMAT", config.OutputFormat), "default audio format requested from ElevenLabs, e.g. mp3_44100_128, pcm_24000, ulaw_8000, or opus_48000_64 (env XI_OUTPUT_FORMAT)")
+	flag.IntVar(&config.ChunkCharacters, "chunk-size", config.ChunkCharacters, "maximum characters per synthesis request when reading long text")
+	flag.IntVar(&config.ChunkConcurrency, "chunk-concurrency", config.ChunkConcurrency, "maximum concurrent synthesis requests when reading long text")
+	flag.StringVar(&config.AudioOutput, "audio-output", envOrDefault("XI_AUDIO_OUTPUT", config.AudioOutput), "playback backend: speaker, null, or wav:<path> (env XI_AUDIO_OUTPUT)")
+	flag.Float64Var(&config.AudioSpeed, "audio-speed", config.AudioSpeed, "playback pace of the null and wav outputs relative to real time")
+	flag.BoolVar(&config.InlineAudio, "inline-audio", envBoolOrDefault("XI_INLINE_AUDIO", config.InlineAudio), "embed generated audio in say and read results by default (env XI_INLINE_AUDIO)")
+	flag.Int64Var(&config.MaxInlineAudioBytes, "max-inline-audio-bytes", config.MaxInlineAudioBytes, "largest clip embedded inline; larger clips are returned as resource links")
+	transport := flag.String("transport", envOrDefault("XI_TRANSPORT", ximcp.TransportStdio), "MCP transport: stdio, http (streamable HTTP), or sse (env XI_TRANSPORT)")
+	address := flag.String("addr", envOrDefault("XI_ADDR", ximcp.DefaultHTTPAddress), "listen address for the http and sse transports (env XI_ADDR)")
+	authToken := flag.String("auth-token", os.Getenv("XI_AUTH_TOKEN"), "bearer token required by the http and sse transports (env XI_AUTH_TOKEN)")
+	flag.Parse()
+
+	switch *transport {
+	case ximcp.TransportStdio, ximcp.TransportHTTP, ximcp.TransportSSE:
+	default:
+		log.Fatalf("Unknown transport %q (expected stdio, http, or sse)", *transport)
+	}
+
 	log.Printf("elevenlabs-mcp %s", version)
+	if configPath != "" {
+		log.Printf("Loaded configuration from %s", configPath)
+	}
 
-	server, err := ximcp.NewServer()
+	server, err := ximcp.NewServer(config)
 	if err != nil {
 		log.Fatalf("Failed to create ElevenLabs server: %v", err)
 	}
 
-	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *transport == ximcp.TransportStdio {
+		err = server.Run(ctx, &mcp.StdioTransport{})
+	} else {
+		log.Printf("Serving MCP over %s on %s", *transport, *address)
+		err = server.ListenAndServe(ctx, *transport, *address, *authToken)
+	}
+	if err != nil && ctx.Err() == nil {
 		log.Fatalf("Failed to serve MCP server: %v", err)
 	}
 }